@@ -130,6 +130,11 @@ type agent struct {
 	sessionToken atomic.Pointer[string]
 	sshServer    *ssh.Server
 
+	// The number of connected clients by type, reported with the stats.
+	sessionCountSSH             atomic.Int64
+	sessionCountReconnectingPTY atomic.Int64
+	sessionCountPortForward     atomic.Int64
+
 	lifecycleUpdate chan struct{}
 	lifecycleMu     sync.Mutex // Protects following.
 	lifecycleState  codersdk.WorkspaceAgentLifecycle
@@ -328,8 +333,11 @@ func (a *agent) run(ctx context.Context) error {
 
 		// Report statistics from the created network.
 		cl, err := a.client.ReportStats(ctx, a.logger, func() *agentsdk.Stats {
-			stats := network.ExtractTrafficStats()
-			return convertAgentStats(stats)
+			stats := convertAgentStats(network.ExtractTrafficStats())
+			stats.SessionCountSSH = a.sessionCountSSH.Load()
+			stats.SessionCountReconnectingPTY = a.sessionCountReconnectingPTY.Load()
+			stats.SessionCountPortForward = a.sessionCountPortForward.Load()
+			return stats
 		})
 		if err != nil {
 			a.logger.Error(ctx, "report stats", slog.Error(err))
@@ -472,6 +480,8 @@ func (a *agent) createTailnet(ctx context.Context, derpMap *tailcfg.DERPMap) (_
 				if err != nil {
 					return
 				}
+				a.sessionCountReconnectingPTY.Add(1)
+				defer a.sessionCountReconnectingPTY.Add(-1)
 				_ = a.handleReconnectingPTY(ctx, logger, msg, conn)
 			}()
 		}
@@ -628,14 +638,20 @@ func (a *agent) init(ctx context.Context) {
 
 	a.sshServer = &ssh.Server{
 		ChannelHandlers: map[string]ssh.ChannelHandler{
-			"direct-tcpip":                   ssh.DirectTCPIPHandler,
-			"direct-streamlocal@openssh.com": directStreamLocalHandler,
-			"session":                        ssh.DefaultSessionHandler,
+			"direct-tcpip": func(srv *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+				ssh.DirectTCPIPHandler(srv, conn, countNewChannel(newChan, &a.sessionCountPortForward), ctx)
+			},
+			"direct-streamlocal@openssh.com": func(srv *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+				directStreamLocalHandler(srv, conn, countNewChannel(newChan, &a.sessionCountPortForward), ctx)
+			},
+			"session": ssh.DefaultSessionHandler,
 		},
 		ConnectionFailedCallback: func(conn net.Conn, err error) {
 			sshLogger.Info(ctx, "ssh connection ended", slog.Error(err))
 		},
 		Handler: func(session ssh.Session) {
+			a.sessionCountSSH.Add(1)
+			defer a.sessionCountSSH.Add(-1)
 			err := a.handleSSHSession(session)
 			var exitError *exec.ExitError
 			if xerrors.As(err, &exitError) {
@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/gliderlabs/ssh"
+	"go.uber.org/atomic"
 	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/xerrors"
 
@@ -201,3 +202,38 @@ func directStreamLocalHandler(_ *ssh.Server, _ *gossh.ServerConn, newChan gossh.
 
 	Bicopy(ctx, ch, dconn)
 }
+
+// countNewChannel counts the channel in count from when it is accepted until
+// it is closed.
+func countNewChannel(newChan gossh.NewChannel, count *atomic.Int64) gossh.NewChannel {
+	return &countedNewChannel{NewChannel: newChan, count: count}
+}
+
+type countedNewChannel struct {
+	gossh.NewChannel
+	count *atomic.Int64
+}
+
+func (c *countedNewChannel) Accept() (gossh.Channel, <-chan *gossh.Request, error) {
+	ch, reqs, err := c.NewChannel.Accept()
+	if err != nil {
+		return nil, nil, err
+	}
+	c.count.Add(1)
+	return &countedChannel{Channel: ch, count: c.count}, reqs, nil
+}
+
+// countedChannel decrements the count once, the handlers close the channel
+// from both copy directions.
+type countedChannel struct {
+	gossh.Channel
+	count *atomic.Int64
+	once  sync.Once
+}
+
+func (c *countedChannel) Close() error {
+	c.once.Do(func() {
+		c.count.Add(-1)
+	})
+	return c.Channel.Close()
+}
@@ -37,8 +37,7 @@ import (
 // @Router /audit [get]
 func (api *API) auditLogs(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceAuditLog) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionRead, rbac.ResourceAuditLog) {
 		return
 	}
 
@@ -99,8 +98,7 @@ func (api *API) auditLogs(rw http.ResponseWriter, r *http.Request) {
 // @Router /audit/testgenerate [post]
 func (api *API) generateFakeAuditLog(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceAuditLog) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceAuditLog) {
 		return
 	}
 
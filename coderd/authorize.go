@@ -54,6 +54,19 @@ func (api *API) Authorize(r *http.Request, action rbac.Action, object rbac.Objec
 	return api.HTTPAuth.Authorize(r, action, object)
 }
 
+// AuthorizeOrForbid is like Authorize, but writes a 403 response when the
+// user is not authorized. The response includes the reason the request was
+// denied. Only use it where the response would be a 403 anyway, as the
+// reason discloses that the resource exists.
+// Eg:
+//
+//	if !api.AuthorizeOrForbid(rw, r, ...) {
+//		return
+//	}
+func (api *API) AuthorizeOrForbid(rw http.ResponseWriter, r *http.Request, action rbac.Action, object rbac.Objecter) bool {
+	return api.HTTPAuth.AuthorizeOrForbid(rw, r, action, object)
+}
+
 // Authorize will return false if the user is not authorized to do the action.
 // This function will log appropriately, but the caller must return an
 // error to the api client.
@@ -64,14 +77,42 @@ func (api *API) Authorize(r *http.Request, action rbac.Action, object rbac.Objec
 //		return
 //	}
 func (h *HTTPAuthorizer) Authorize(r *http.Request, action rbac.Action, object rbac.Objecter) bool {
+	return h.authorize(r, action, object) == nil
+}
+
+// AuthorizeOrForbid is like Authorize, but writes a 403 response with the
+// reason the request was denied.
+func (h *HTTPAuthorizer) AuthorizeOrForbid(rw http.ResponseWriter, r *http.Request, action rbac.Action, object rbac.Objecter) bool {
+	err := h.authorize(r, action, object)
+	if err == nil {
+		return true
+	}
+
+	var detail string
+	var unauthorized *rbac.UnauthorizedError
+	if xerrors.As(err, &unauthorized) && unauthorized.Reason() != rbac.DenyReasonUnknown {
+		detail = unauthorized.Reason().Message()
+	}
+	httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+		Message: "Forbidden.",
+		Detail:  detail,
+	})
+	return false
+}
+
+// authorize returns the authorization error, after logging it.
+func (h *HTTPAuthorizer) authorize(r *http.Request, action rbac.Action, object rbac.Objecter) error {
 	roles := httpmw.UserAuthorization(r)
 	err := h.Authorizer.Authorize(r.Context(), roles.Actor, action, object.RBACObject())
 	if err != nil {
 		// Log the errors for debugging
-		internalError := new(rbac.UnauthorizedError)
+		var internalError *rbac.UnauthorizedError
 		logger := h.Logger
-		if xerrors.As(err, internalError) {
-			logger = h.Logger.With(slog.F("internal", internalError.Internal()))
+		if xerrors.As(err, &internalError) {
+			logger = h.Logger.With(
+				slog.F("internal", internalError.Internal()),
+				slog.F("reason", internalError.Reason()),
+			)
 		}
 		// Log information for debugging. This will be very helpful
 		// in the early days
@@ -85,9 +126,9 @@ func (h *HTTPAuthorizer) Authorize(r *http.Request, action rbac.Action, object r
 			slog.F("object", object),
 		)
 
-		return false
+		return err
 	}
-	return true
+	return nil
 }
 
 // AuthorizeSQLFilter returns an authorization filter that can used in a
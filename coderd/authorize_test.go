@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/google/uuid"
@@ -139,3 +140,39 @@ func TestCheckPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizeDenyReason(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	t.Cleanup(cancel)
+
+	client := coderdtest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+
+	t.Run("Role", func(t *testing.T) {
+		t.Parallel()
+		member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		_, err := member.DeploymentConfig(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		require.Equal(t, rbac.DenyReasonInsufficientRole.Message(), apiErr.Detail)
+	})
+
+	t.Run("Scope", func(t *testing.T) {
+		t.Parallel()
+		res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope: codersdk.APIKeyScopeApplicationConnect,
+		})
+		require.NoError(t, err)
+		scoped := codersdk.New(client.URL)
+		scoped.SetSessionToken(res.Key)
+
+		_, err = scoped.DeploymentConfig(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		require.Equal(t, rbac.DenyReasonOutOfScope.Message(), apiErr.Detail)
+	})
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/util/slice"
+)
+
+// AffectedSubjects returns the IDs of the users that hold the site role,
+// either directly or through membership of a group it is granted to. Use it
+// to find who a change to the role would affect. The database does not
+// store group roles, so they are passed in as groupRoles, keyed by group ID
+// like rbac.Subject.GroupRoles. The IDs are de-duplicated and sorted.
+func AffectedSubjects(ctx context.Context, db Store, groupRoles map[string]rbac.ExpandableRoles, roleName string) ([]uuid.UUID, error) {
+	if _, ok := rbac.IsOrgRole(roleName); ok {
+		// Organization roles are stored on the memberships, which cannot be
+		// filtered by role.
+		return nil, xerrors.Errorf("role %q is an organization role, only site roles are supported", roleName)
+	}
+
+	affected := map[uuid.UUID]struct{}{}
+	users, err := db.GetUsers(ctx, GetUsersParams{
+		RbacRole: []string{roleName},
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("get users with role: %w", err)
+	}
+	for _, user := range users {
+		affected[user.ID] = struct{}{}
+	}
+
+	for groupID, roles := range groupRoles {
+		if roles == nil || !slice.Contains(roles.Names(), roleName) {
+			continue
+		}
+		id, err := uuid.Parse(groupID)
+		if err != nil {
+			return nil, xerrors.Errorf("parse group id %q: %w", groupID, err)
+		}
+		group, err := db.GetGroupByID(ctx, id)
+		if err != nil {
+			return nil, xerrors.Errorf("get group %q: %w", groupID, err)
+		}
+
+		var members []User
+		if group.Name == AllUsersGroup {
+			members, err = db.GetAllOrganizationMembers(ctx, group.OrganizationID)
+		} else {
+			members, err = db.GetGroupMembers(ctx, group.ID)
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("get members of group %q: %w", groupID, err)
+		}
+		for _, member := range members {
+			affected[member.ID] = struct{}{}
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(affected))
+	for id := range affected {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids, nil
+}
@@ -0,0 +1,78 @@
+package database_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestAffectedSubjects(t *testing.T) {
+	t.Parallel()
+
+	ctx, _ := testutil.Context(t)
+	db := databasefake.New()
+	orgID := uuid.New()
+
+	user := func(name string, roles ...string) database.User {
+		u, err := db.InsertUser(ctx, database.InsertUserParams{
+			ID:        uuid.New(),
+			Email:     name + "@coder.com",
+			Username:  name,
+			RBACRoles: roles,
+			LoginType: database.LoginTypePassword,
+		})
+		require.NoError(t, err)
+		return u
+	}
+	group := func(name string, members ...database.User) database.Group {
+		g, err := db.InsertGroup(ctx, database.InsertGroupParams{
+			ID:             uuid.New(),
+			Name:           name,
+			OrganizationID: orgID,
+		})
+		require.NoError(t, err)
+		for _, member := range members {
+			err := db.InsertGroupMember(ctx, database.InsertGroupMemberParams{
+				UserID:  member.ID,
+				GroupID: g.ID,
+			})
+			require.NoError(t, err)
+		}
+		return g
+	}
+
+	direct := user("direct", rbac.RoleTemplateAdmin())
+	viaGroup := user("via-group")
+	both := user("both", rbac.RoleTemplateAdmin())
+	unrelated := user("unrelated", rbac.RoleUserAdmin())
+
+	templateAdmins := group("template-admins", viaGroup, both)
+	userAdmins := group("user-admins", unrelated)
+	groupRoles := map[string]rbac.ExpandableRoles{
+		templateAdmins.ID.String(): rbac.RoleNames{rbac.RoleTemplateAdmin()},
+		userAdmins.ID.String():     rbac.RoleNames{rbac.RoleUserAdmin()},
+	}
+
+	affected, err := database.AffectedSubjects(ctx, db, groupRoles, rbac.RoleTemplateAdmin())
+	require.NoError(t, err)
+	expected := []uuid.UUID{direct.ID, viaGroup.ID, both.ID}
+	sort.Slice(expected, func(i, j int) bool {
+		return expected[i].String() < expected[j].String()
+	})
+	require.Equal(t, expected, affected)
+
+	// A role no one holds affects no one.
+	affected, err = database.AffectedSubjects(ctx, db, groupRoles, "unused")
+	require.NoError(t, err)
+	require.Empty(t, affected)
+
+	_, err = database.AffectedSubjects(ctx, db, groupRoles, rbac.RoleOrgAdmin(orgID))
+	require.Error(t, err)
+}
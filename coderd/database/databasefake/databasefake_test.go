@@ -8,9 +8,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/rbac/regosql"
+	"github.com/coder/coder/testutil"
 
 	"github.com/coder/coder/coderd/database/databasefake"
 )
@@ -112,3 +119,96 @@ func methods(rt reflect.Type) map[string]bool {
 	}
 	return methods
 }
+
+// TestGetAuthorizedWorkspaces ensures the fake applies the authorization
+// predicate passed to authorized list queries. This allows authz filtered
+// list tests to run without a real database.
+func TestGetAuthorizedWorkspaces(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	t.Cleanup(cancel)
+	db := databasefake.New()
+
+	orgA, orgB := uuid.New(), uuid.New()
+	me, other := uuid.New(), uuid.New()
+	var workspaces []database.Workspace
+	for _, org := range []uuid.UUID{orgA, orgB} {
+		for _, owner := range []uuid.UUID{me, other} {
+			workspace, err := db.InsertWorkspace(ctx, database.InsertWorkspaceParams{
+				ID:             uuid.New(),
+				OwnerID:        owner,
+				OrganizationID: org,
+				Name:           uuid.NewString()[:8],
+			})
+			require.NoError(t, err)
+			workspaces = append(workspaces, workspace)
+		}
+	}
+
+	subject := rbac.Subject{
+		ID:    me.String(),
+		Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgA)},
+		Scope: rbac.ScopeAll,
+	}
+
+	t.Run("Authorizer", func(t *testing.T) {
+		t.Parallel()
+
+		auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+		prepared, err := auth.Prepare(ctx, subject, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+		require.NoError(t, err)
+
+		rows, err := db.GetAuthorizedWorkspaces(ctx, database.GetWorkspacesParams{}, prepared)
+		require.NoError(t, err)
+
+		expected, err := rbac.Filter(ctx, auth, subject, rbac.ActionRead, workspaces)
+		require.NoError(t, err)
+		require.Len(t, expected, 1, "only the workspace owned by the subject in their org")
+		require.ElementsMatch(t, workspaceIDs(expected), rowIDs(rows))
+	})
+
+	t.Run("Predicate", func(t *testing.T) {
+		t.Parallel()
+
+		// Any predicate can be pushed into the fake.
+		rows, err := db.GetAuthorizedWorkspaces(ctx, database.GetWorkspacesParams{}, rowFilter(func(object rbac.Object) bool {
+			return object.OrgID == orgB.String()
+		}))
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		for _, row := range rows {
+			require.Equal(t, orgB, row.OrganizationID)
+		}
+	})
+}
+
+// rowFilter is a predicate that implements rbac.PreparedAuthorized.
+type rowFilter func(object rbac.Object) bool
+
+func (f rowFilter) Authorize(_ context.Context, object rbac.Object) error {
+	if !f(object) {
+		return rbac.ForbiddenWithInternal(xerrors.New("filtered"), nil, nil)
+	}
+	return nil
+}
+
+func (rowFilter) CompileToSQL(_ context.Context, _ regosql.ConvertConfig) (string, error) {
+	return "", xerrors.New("not implemented")
+}
+
+func workspaceIDs(workspaces []database.Workspace) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(workspaces))
+	for _, w := range workspaces {
+		ids = append(ids, w.ID)
+	}
+	return ids
+}
+
+func rowIDs(rows []database.GetWorkspacesRow) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, w := range rows {
+		ids = append(ids, w.ID)
+	}
+	return ids
+}
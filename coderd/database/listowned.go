@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+// ListOwned returns the IDs of the resources of the given type that the
+// subject owns and can read. Resources the subject can read but does not
+// own, eg the workspaces of other users for an owner, are not included.
+// Only workspaces are supported.
+func ListOwned(ctx context.Context, db Store, auth rbac.Authorizer, subject rbac.Subject, resourceType string) ([]uuid.UUID, error) {
+	ownerID, err := uuid.Parse(subject.ID)
+	if err != nil {
+		return nil, xerrors.Errorf("parse subject id %q: %w", subject.ID, err)
+	}
+
+	switch resourceType {
+	case rbac.ResourceWorkspace.Type:
+		rows, err := db.GetWorkspaces(ctx, GetWorkspacesParams{
+			OwnerID: ownerID,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("get workspaces: %w", err)
+		}
+		workspaces, err := rbac.Filter(ctx, auth, subject, rbac.ActionRead, ConvertWorkspaceRows(rows))
+		if err != nil {
+			return nil, xerrors.Errorf("filter workspaces: %w", err)
+		}
+		ids := make([]uuid.UUID, 0, len(workspaces))
+		for _, workspace := range workspaces {
+			ids = append(ids, workspace.ID)
+		}
+		return ids, nil
+	default:
+		return nil, xerrors.Errorf("listing owned %q resources is not supported", resourceType)
+	}
+}
@@ -0,0 +1,88 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/databasefake"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestListOwned(t *testing.T) {
+	t.Parallel()
+
+	ctx, _ := testutil.Context(t)
+	db := databasefake.New()
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	orgID := uuid.New()
+	templateID := uuid.New()
+
+	workspace := func(ownerID uuid.UUID, name string) uuid.UUID {
+		w, err := db.InsertWorkspace(ctx, database.InsertWorkspaceParams{
+			ID:             uuid.New(),
+			OwnerID:        ownerID,
+			OrganizationID: orgID,
+			TemplateID:     templateID,
+			Name:           name,
+		})
+		require.NoError(t, err)
+		return w.ID
+	}
+
+	me := uuid.New()
+	other := uuid.New()
+	mine := []uuid.UUID{workspace(me, "first"), workspace(me, "second")}
+	_ = workspace(other, "first")
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		owned, err := database.ListOwned(ctx, db, auth, rbac.Subject{
+			ID:    me.String(),
+			Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgID)},
+			Scope: rbac.ScopeAll,
+		}, rbac.ResourceWorkspace.Type)
+		require.NoError(t, err)
+		require.ElementsMatch(t, mine, owned)
+	})
+
+	// An owner can read every workspace, but only owns their own.
+	t.Run("Owner", func(t *testing.T) {
+		t.Parallel()
+		owned, err := database.ListOwned(ctx, db, auth, rbac.Subject{
+			ID:    other.String(),
+			Roles: rbac.RoleNames{rbac.RoleOwner(), rbac.RoleMember()},
+			Scope: rbac.ScopeAll,
+		}, rbac.ResourceWorkspace.Type)
+		require.NoError(t, err)
+		require.Len(t, owned, 1)
+		require.NotContains(t, owned, mine[0])
+		require.NotContains(t, owned, mine[1])
+	})
+
+	// The scope still applies to owned workspaces.
+	t.Run("Scope", func(t *testing.T) {
+		t.Parallel()
+		owned, err := database.ListOwned(ctx, db, auth, rbac.Subject{
+			ID:    me.String(),
+			Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgID)},
+			Scope: rbac.ScopeApplicationConnect,
+		}, rbac.ResourceWorkspace.Type)
+		require.NoError(t, err)
+		require.Empty(t, owned)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		t.Parallel()
+		_, err := database.ListOwned(ctx, db, auth, rbac.Subject{
+			ID:    me.String(),
+			Roles: rbac.RoleNames{rbac.RoleMember()},
+			Scope: rbac.ScopeAll,
+		}, rbac.ResourceTemplate.Type)
+		require.Error(t, err)
+	})
+}
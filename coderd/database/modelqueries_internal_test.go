@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
 )
 
 func TestIsAuthorizedQuery(t *testing.T) {
@@ -13,3 +15,15 @@ func TestIsAuthorizedQuery(t *testing.T) {
 	_, err := insertAuthorizedFilter(query, "")
 	require.ErrorContains(t, err, "does not contain authorized replace string", "ensure replace string")
 }
+
+// TestAPIKeyScopesParse ensures every API key scope stored in the database is
+// a built-in scope, as the API key middleware rejects unknown scopes.
+func TestAPIKeyScopesParse(t *testing.T) {
+	t.Parallel()
+
+	for _, scope := range AllAPIKeyScopeValues() {
+		parsed, err := rbac.ParseScope(string(scope))
+		require.NoError(t, err, scope)
+		require.Equal(t, scope.ToRBAC(), parsed, scope)
+	}
+}
@@ -15,8 +15,7 @@ import (
 // @Success 200 {object} codersdk.DeploymentConfig
 // @Router /config/deployment [get]
 func (api *API) deploymentConfig(rw http.ResponseWriter, r *http.Request) {
-	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDeploymentConfig) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionRead, rbac.ResourceDeploymentConfig) {
 		return
 	}
 
@@ -39,8 +39,7 @@ func (api *API) postFile(rw http.ResponseWriter, r *http.Request) {
 	apiKey := httpmw.APIKey(r)
 	// This requires the site wide action to create files.
 	// Once created, a user can read their own files uploaded
-	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
 		return
 	}
 
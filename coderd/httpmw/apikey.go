@@ -340,6 +340,17 @@ func ExtractAPIKey(cfg ExtractAPIKeyConfig) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Every scope the database accepts is a built-in scope, so an
+			// unknown scope is a bug rather than a bad key.
+			scope, err := rbac.ParseScope(string(key.Scope))
+			if err != nil {
+				write(http.StatusInternalServerError, codersdk.Response{
+					Message: internalErrorMessage,
+					Detail:  fmt.Sprintf("API key has a scope the server does not know: %s", err.Error()),
+				})
+				return
+			}
+
 			ctx = context.WithValue(ctx, apiKeyContextKey{}, key)
 			ctx = context.WithValue(ctx, userAuthKey{}, Authorization{
 				Username: roles.Username,
@@ -347,7 +358,7 @@ func ExtractAPIKey(cfg ExtractAPIKeyConfig) func(http.Handler) http.Handler {
 					ID:     key.UserID.String(),
 					Roles:  rbac.RoleNames(roles.Roles),
 					Groups: roles.Groups,
-					Scope:  rbac.ScopeName(key.Scope),
+					Scope:  scope,
 				},
 			})
 
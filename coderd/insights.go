@@ -17,8 +17,7 @@ import (
 // @Router /insights/daus [get]
 func (api *API) deploymentDAUs(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDeploymentConfig) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionRead, rbac.ResourceDeploymentConfig) {
 		return
 	}
 
@@ -54,14 +54,12 @@ func (api *API) putMemberRoles(rw http.ResponseWriter, r *http.Request) {
 	added, removed := rbac.ChangeRoleSet(member.Roles, impliedTypes)
 
 	// Assigning a role requires the create permission.
-	if len(added) > 0 && !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrgRoleAssignment.InOrg(organization.ID)) {
-		httpapi.Forbidden(rw)
+	if len(added) > 0 && !api.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceOrgRoleAssignment.InOrg(organization.ID)) {
 		return
 	}
 
 	// Removing a role requires the delete permission.
-	if len(removed) > 0 && !api.Authorize(r, rbac.ActionDelete, rbac.ResourceOrgRoleAssignment.InOrg(organization.ID)) {
-		httpapi.Forbidden(rw)
+	if len(removed) > 0 && !api.AuthorizeOrForbid(rw, r, rbac.ActionDelete, rbac.ResourceOrgRoleAssignment.InOrg(organization.ID)) {
 		return
 	}
 
@@ -115,6 +113,8 @@ func (api *API) updateOrganizationMemberRoles(ctx context.Context, args database
 	if err != nil {
 		return database.OrganizationMember{}, xerrors.Errorf("Update site roles: %w", err)
 	}
+	// Free the decisions cached with the old roles.
+	rbac.InvalidateSubject(api.Authorizer, updatedUser.UserID.String())
 	return updatedUser, nil
 }
 
@@ -50,8 +50,7 @@ func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
 	apiKey := httpmw.APIKey(r)
 	// Create organization uses the organization resource without an OrgID.
 	// This means you need the site wide permission to make a new organization.
-	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrganization) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceOrganization) {
 		return
 	}
 
@@ -9,3 +9,8 @@ const (
 	ActionUpdate = "update"
 	ActionDelete = "delete"
 )
+
+// AllActions returns every action.
+func AllActions() []Action {
+	return []Action{ActionCreate, ActionRead, ActionUpdate, ActionDelete}
+}
@@ -24,6 +24,36 @@ type Subject struct {
 	Roles  ExpandableRoles
 	Groups []string
 	Scope  ExpandableScope
+	// GroupRoles are roles granted to groups, keyed by group ID. The subject
+	// inherits the roles of every group in Groups. Group roles are combined
+	// with the direct roles before evaluation, so they follow the same
+	// precedence as any other role: a negative permission denies at its
+	// level, even if a direct role allows it.
+	GroupRoles map[string]ExpandableRoles
+}
+
+// expandRoles returns the subject's direct roles and the roles of the
+// groups the subject is a member of.
+func (s Subject) expandRoles() ([]Role, error) {
+	direct, err := s.Roles.Expand()
+	if err != nil {
+		return nil, err
+	}
+	// Copy the roles so appending never modifies the slice the direct
+	// roles expanded to.
+	roles := append([]Role{}, direct...)
+	for _, group := range s.Groups {
+		groupRoles, ok := s.GroupRoles[group]
+		if !ok || groupRoles == nil {
+			continue
+		}
+		expanded, err := groupRoles.Expand()
+		if err != nil {
+			return nil, xerrors.Errorf("group %q: %w", group, err)
+		}
+		roles = append(roles, expanded...)
+	}
+	return roles, nil
 }
 
 // SafeScopeName prevent nil pointer dereference.
@@ -134,6 +164,11 @@ var (
 	policy    string
 	queryOnce sync.Once
 	query     rego.PreparedEvalQuery
+
+	// reasonQuery evaluates all rules of the policy to find the reason for
+	// a denial.
+	reasonQueryOnce sync.Once
+	reasonQuery     rego.PreparedEvalQuery
 )
 
 func NewAuthorizer(registry prometheus.Registerer) *RegoAuthorizer {
@@ -243,7 +278,7 @@ func (a RegoAuthorizer) authorize(ctx context.Context, subject Subject, action A
 		return xerrors.Errorf("subject must have a scope")
 	}
 
-	subjRoles, err := subject.Roles.Expand()
+	subjRoles, err := subject.expandRoles()
 	if err != nil {
 		return xerrors.Errorf("expand roles: %w", err)
 	}
@@ -264,17 +299,75 @@ func (a RegoAuthorizer) authorize(ctx context.Context, subject Subject, action A
 		"action": action,
 	}
 
+	if object.Parent != nil {
+		return authorizePath(ctx, input, object)
+	}
+
 	results, err := a.query.Eval(ctx, rego.EvalInput(input))
 	if err != nil {
 		return ForbiddenWithInternal(xerrors.Errorf("eval rego: %w", err), input, results)
 	}
 
 	if !results.Allowed() {
-		return ForbiddenWithInternal(xerrors.Errorf("policy disallows request"), input, results)
+		return ForbiddenWithInternal(xerrors.Errorf("policy disallows request"), input, results).withLazyReason(lazyDenyReason(input))
 	}
 	return nil
 }
 
+// lazyDenyReason returns a function that finds the reason for a denied
+// request. The reason may be needed after the request's context is done, so
+// it is found without it.
+func lazyDenyReason(input map[string]interface{}) func() DenyReason {
+	return func() DenyReason {
+		return denyReason(context.Background(), input)
+	}
+}
+
+// denyReason evaluates the individual rules of the policy to find why a
+// request was denied. This is only done for denied requests, so allowed
+// requests do not pay the cost.
+func denyReason(ctx context.Context, input map[string]interface{}) DenyReason {
+	rules, err := policyRules(ctx, input)
+	if err != nil {
+		return DenyReasonUnknown
+	}
+	// Rules that are not true are undefined, so they are absent.
+	if rules["role_allow"] != true && rules["acl_allow"] != true {
+		return DenyReasonInsufficientRole
+	}
+	if rules["scope_allow"] != true {
+		return DenyReasonOutOfScope
+	}
+	return DenyReasonUnknown
+}
+
+// policyRules evaluates all rules of the policy, keyed by rule name.
+func policyRules(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+	reasonQueryOnce.Do(func() {
+		var err error
+		reasonQuery, err = rego.New(
+			rego.Query("data.authz"),
+			rego.Module("policy.rego", policy),
+		).PrepareForEval(context.Background())
+		if err != nil {
+			panic(xerrors.Errorf("compile rego: %w", err))
+		}
+	})
+
+	results, err := reasonQuery.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, xerrors.Errorf("eval rego: %w", err)
+	}
+	if len(results) != 1 || len(results[0].Expressions) != 1 {
+		return nil, xerrors.Errorf("expected 1 result, got %d", len(results))
+	}
+	rules, ok := results[0].Expressions[0].Value.(map[string]interface{})
+	if !ok {
+		return nil, xerrors.Errorf("unexpected result type %T", results[0].Expressions[0].Value)
+	}
+	return rules, nil
+}
+
 // Prepare will partially execute the rego policy leaving the object fields unknown (except for the type).
 // This will vastly speed up performance if batch authorization on the same type of objects is needed.
 func (a RegoAuthorizer) Prepare(ctx context.Context, subject Subject, action Action, objectType string) (PreparedAuthorized, error) {
@@ -47,6 +47,30 @@ func TestFilterError(t *testing.T) {
 // TestFilter ensures the filter acts the same as an individual authorize.
 // It generates a random set of objects, then runs the Filter batch function
 // against the singular Authorize function.
+// TestFilterDenyReason ensures filtering does not find the reason objects
+// are denied, as it evaluates the policy again for each object.
+func TestFilterDenyReason(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	subject := Subject{
+		ID:    uuid.NewString(),
+		Roles: RoleNames{RoleMember()},
+		Scope: ScopeAll,
+	}
+	prepared, err := NewAuthorizer(prometheus.NewRegistry()).Prepare(ctx, subject, ActionRead, ResourceWorkspace.Type)
+	require.NoError(t, err)
+
+	err = prepared.Authorize(ctx, ResourceWorkspace.WithOwner(uuid.NewString()))
+	var uerr *UnauthorizedError
+	require.ErrorAs(t, err, &uerr)
+	require.NotNil(t, uerr.lazyReason)
+	require.Empty(t, uerr.lazyReason.reason, "not found until needed")
+	require.Equal(t, DenyReasonInsufficientRole, uerr.Reason())
+}
+
 func TestFilter(t *testing.T) {
 	t.Parallel()
 
@@ -943,6 +967,278 @@ func TestAuthorizeScope(t *testing.T) {
 	)
 }
 
+func TestAuthorizeCustomScope(t *testing.T) {
+	t.Parallel()
+
+	defOrg := uuid.New()
+	unusedID := uuid.New()
+
+	// A service account with a broad role, but a scope that can only create
+	// workspaces.
+	serviceAccount := Subject{
+		ID: "ci",
+		Roles: Roles{
+			must(RoleByName(RoleOwner())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+		},
+		Scope: CustomScope("ci", "CI Service Account", nil,
+			ScopeRule{ResourceType: ResourceWorkspace.Type, Action: ActionCreate},
+		),
+	}
+
+	testAuthorize(t, "ServiceAccount", serviceAccount,
+		// Denied by the scope, even though the role allows it.
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []Action{ActionRead, ActionUpdate, ActionDelete}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(serviceAccount.ID)},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me")},
+			{resource: ResourceWorkspace.All()},
+		}),
+		cases(func(c authTestCase) authTestCase {
+			c.actions = allActions()
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceTemplate.InOrg(defOrg)},
+			{resource: ResourceUser},
+			{resource: ResourceWorkspaceExecution.InOrg(defOrg).WithOwner(serviceAccount.ID)},
+			{resource: ResourceWorkspaceApplicationConnect.InOrg(defOrg).WithOwner(serviceAccount.ID)},
+		}),
+		// Allowed by both the role and the scope.
+		[]authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(serviceAccount.ID), actions: []Action{ActionCreate}, allow: true},
+			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner("not-me"), actions: []Action{ActionCreate}, allow: true},
+		},
+	)
+
+	// The same scope on a member can only create workspaces it owns, as the
+	// role still applies.
+	member := Subject{
+		ID: "me",
+		Roles: Roles{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+		},
+		Scope: serviceAccount.Scope,
+	}
+	testAuthorize(t, "MemberServiceAccount", member,
+		[]authTestCase{
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(member.ID), actions: []Action{ActionCreate}, allow: true},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(member.ID), actions: []Action{ActionRead, ActionUpdate, ActionDelete}, allow: false},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me"), actions: []Action{ActionCreate}, allow: false},
+		},
+	)
+}
+
+func TestAuthorizeGroupRoles(t *testing.T) {
+	t.Parallel()
+
+	defOrg := uuid.New()
+	group := uuid.NewString()
+
+	templateViewer := Role{
+		Name: "template-viewer",
+		Site: []Permission{
+			{ResourceType: ResourceTemplate.Type, Action: ActionRead},
+		},
+	}
+	groupRoles := map[string]ExpandableRoles{
+		group: Roles{templateViewer},
+	}
+
+	// The permission is only granted via group membership.
+	member := Subject{
+		ID: "me",
+		Roles: Roles{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+		},
+		Groups:     []string{group},
+		GroupRoles: groupRoles,
+		Scope:      must(ExpandScope(ScopeAll)),
+	}
+	testAuthorize(t, "GroupMember", member,
+		[]authTestCase{
+			{resource: ResourceTemplate.InOrg(defOrg), actions: []Action{ActionRead}, allow: true},
+			{resource: ResourceTemplate.InOrg(defOrg), actions: []Action{ActionCreate, ActionUpdate, ActionDelete}, allow: false},
+		},
+	)
+
+	// Without membership, the group's roles do not apply.
+	nonMember := member
+	nonMember.Groups = []string{uuid.NewString()}
+	testAuthorize(t, "NotGroupMember", nonMember,
+		[]authTestCase{
+			{resource: ResourceTemplate.InOrg(defOrg), actions: allActions(), allow: false},
+		},
+	)
+
+	// A negative permission granted to a group overrides an allow from a
+	// direct role at the same level.
+	noTemplates := Role{
+		Name: "no-templates",
+		Site: []Permission{
+			{Negate: true, ResourceType: ResourceTemplate.Type, Action: WildcardSymbol},
+		},
+	}
+	owner := Subject{
+		ID: "owner",
+		Roles: Roles{
+			must(RoleByName(RoleOwner())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+		},
+		Groups: []string{group},
+		GroupRoles: map[string]ExpandableRoles{
+			group: Roles{noTemplates},
+		},
+		Scope: must(ExpandScope(ScopeAll)),
+	}
+	testAuthorize(t, "GroupDeny", owner,
+		[]authTestCase{
+			{resource: ResourceTemplate.InOrg(defOrg), actions: allActions(), allow: false},
+			{resource: ResourceTemplate.All(), actions: allActions(), allow: false},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner("not-me"), actions: allActions(), allow: true},
+		},
+	)
+}
+
+func TestAuthorizeTemplateVersion(t *testing.T) {
+	t.Parallel()
+
+	defOrg := uuid.New()
+
+	// A role that can only promote template versions.
+	promoter := Subject{
+		ID: "me",
+		Roles: Roles{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(RoleOrgMember(defOrg))),
+			{
+				Name: "version-promoter",
+				Org: map[string][]Permission{
+					defOrg.String(): {
+						{ResourceType: ResourceTemplateVersion.Type, Action: ActionUpdate},
+					},
+				},
+			},
+		},
+		Scope: must(ExpandScope(ScopeAll)),
+	}
+
+	version := ResourceTemplateVersion.WithID(uuid.New()).InOrg(defOrg)
+	template := ResourceTemplate.WithID(uuid.New()).InOrg(defOrg)
+	testAuthorize(t, "VersionPromoter", promoter,
+		[]authTestCase{
+			// Promote
+			{resource: version, actions: []Action{ActionUpdate}, allow: true},
+			// Archive
+			{resource: version, actions: []Action{ActionDelete}, allow: false},
+			{resource: version, actions: []Action{ActionCreate}, allow: false},
+			{resource: version, actions: []Action{ActionRead}, allow: false},
+			// No control over the template itself.
+			{resource: template, actions: []Action{ActionDelete}, allow: false},
+			{resource: template, actions: []Action{ActionUpdate}, allow: false},
+			// Other organizations are not affected.
+			{resource: ResourceTemplateVersion.InOrg(uuid.New()), actions: []Action{ActionUpdate}, allow: false},
+		},
+	)
+}
+
+func TestAuthorizePath(t *testing.T) {
+	t.Parallel()
+
+	workspace := ResourceWorkspace.WithID(uuid.New())
+	agent := Object{Type: "workspace_agent"}.WithID(uuid.New()).WithParent(workspace)
+	app := Object{Type: "workspace_app"}.WithID(uuid.New()).WithParent(agent)
+	require.Equal(t, "workspace/"+workspace.ID+"/workspace_agent/"+agent.ID+"/workspace_app/"+app.ID, app.Path())
+
+	workspaceReader := Role{
+		Name: "workspace-reader",
+		Site: []Permission{
+			{ResourceType: ResourceWorkspace.Type, Action: ActionRead},
+		},
+	}
+	noAgents := Role{
+		Name: "no-agents",
+		Site: []Permission{
+			{Negate: true, ResourceType: "workspace_agent", Action: ActionRead},
+		},
+	}
+
+	authorizer := NewAuthorizer(prometheus.NewRegistry())
+	authorize := func(t *testing.T, roles Roles, object Object) error {
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		t.Cleanup(cancel)
+		return authorizer.Authorize(ctx, Subject{
+			ID:    "me",
+			Roles: roles,
+			Scope: must(ExpandScope(ScopeAll)),
+		}, ActionRead, object)
+	}
+
+	t.Run("ParentGrants", func(t *testing.T) {
+		t.Parallel()
+		roles := Roles{workspaceReader}
+		require.NoError(t, authorize(t, roles, workspace))
+		require.NoError(t, authorize(t, roles, agent))
+		require.NoError(t, authorize(t, roles, app))
+		// Without the parent, the agent is not allowed.
+		require.Error(t, authorize(t, roles, Object{Type: "workspace_agent"}.WithID(uuid.New())))
+	})
+
+	t.Run("ChildDenies", func(t *testing.T) {
+		t.Parallel()
+		roles := Roles{workspaceReader, noAgents}
+		require.NoError(t, authorize(t, roles, workspace))
+		err := authorize(t, roles, agent)
+		require.Error(t, err)
+		var unauthorized *UnauthorizedError
+		require.ErrorAs(t, err, &unauthorized)
+		require.Equal(t, DenyReasonInsufficientRole, unauthorized.Reason())
+		// The deny on the agent applies to its children too.
+		require.Error(t, authorize(t, roles, app))
+	})
+
+	// Filter prepares the authorizer for 10 or more objects, which must
+	// give the same result as authorizing each object.
+	t.Run("Filter", func(t *testing.T) {
+		t.Parallel()
+		ownWorkspaces := Role{
+			Name: "own-workspaces",
+			User: []Permission{
+				{ResourceType: ResourceWorkspace.Type, Action: ActionRead},
+			},
+		}
+		subject := Subject{
+			ID:    "me",
+			Roles: Roles{ownWorkspaces},
+			Scope: must(ExpandScope(ScopeAll)),
+		}
+		children := make([]Object, 0, 12)
+		for i := 0; i < 12; i++ {
+			parent := ResourceWorkspace.WithID(uuid.New()).WithOwner("me")
+			if i%2 == 1 {
+				parent = parent.WithOwner(uuid.NewString())
+			}
+			children = append(children, Object{Type: "workspace_agent"}.WithID(uuid.New()).WithParent(parent))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+		few, err := Filter(ctx, authorizer, subject, ActionRead, children[:9])
+		require.NoError(t, err)
+		many, err := Filter(ctx, authorizer, subject, ActionRead, children)
+		require.NoError(t, err)
+
+		// Children of owned workspaces.
+		require.Equal(t, []Object{children[0], children[2], children[4], children[6], children[8]}, few)
+		require.Equal(t, []Object{children[0], children[2], children[4], children[6], children[8], children[10]}, many)
+	})
+}
+
 // cases applies a given function to all test cases. This makes generalities easier to create.
 func cases(opt func(c authTestCase) authTestCase, cases []authTestCase) []authTestCase {
 	if opt == nil {
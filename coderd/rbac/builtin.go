@@ -110,8 +110,9 @@ var (
 				Site: permissions(map[string][]Action{
 					// Should be able to read all template details, even in orgs they
 					// are not in.
-					ResourceTemplate.Type: {ActionRead},
-					ResourceAuditLog.Type: {ActionRead},
+					ResourceTemplate.Type:        {ActionRead},
+					ResourceTemplateVersion.Type: {ActionRead},
+					ResourceAuditLog.Type:        {ActionRead},
 				}),
 			}
 		},
@@ -121,7 +122,8 @@ var (
 				Name:        templateAdmin,
 				DisplayName: "Template Admin",
 				Site: permissions(map[string][]Action{
-					ResourceTemplate.Type: {ActionCreate, ActionRead, ActionUpdate, ActionDelete},
+					ResourceTemplate.Type:        {ActionCreate, ActionRead, ActionUpdate, ActionDelete},
+					ResourceTemplateVersion.Type: {ActionCreate, ActionRead, ActionUpdate, ActionDelete},
 					// CRUD all files, even those they did not upload.
 					ResourceFile.Type:      {ActionCreate, ActionRead, ActionUpdate, ActionDelete},
 					ResourceWorkspace.Type: {ActionRead},
@@ -142,6 +142,15 @@ func TestRolePermissions(t *testing.T) {
 				false: {memberMe, otherOrgAdmin, otherOrgMember, userAdmin, orgMemberMe},
 			},
 		},
+		{
+			Name:     "TemplateVersions",
+			Actions:  []rbac.Action{rbac.ActionCreate, rbac.ActionRead, rbac.ActionUpdate, rbac.ActionDelete},
+			Resource: rbac.ResourceTemplateVersion.WithID(uuid.New()).InOrg(orgID),
+			AuthorizeMap: map[bool][]authSubject{
+				true:  {owner, orgAdmin, templateAdmin},
+				false: {memberMe, orgMemberMe, otherOrgAdmin, otherOrgMember, userAdmin},
+			},
+		},
 		{
 			Name:     "Files",
 			Actions:  []rbac.Action{rbac.ActionCreate},
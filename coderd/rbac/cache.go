@@ -0,0 +1,179 @@
+package rbac
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// SubjectInvalidator is implemented by authorizers that cache decisions for
+// subjects.
+type SubjectInvalidator interface {
+	// InvalidateSubject removes all cached decisions for the subject.
+	InvalidateSubject(subjectID string)
+}
+
+// InvalidateSubject removes the cached decisions for the subject if the
+// authorizer, or an authorizer it wraps, caches decisions. Decisions are
+// cached by the subject's roles, so this only frees the decisions of roles
+// the subject no longer has.
+func InvalidateSubject(auth Authorizer, subjectID string) {
+	if invalidator, ok := auth.(SubjectInvalidator); ok {
+		invalidator.InvalidateSubject(subjectID)
+	}
+}
+
+// WithCache caches the decisions of Authorize calls for the ttl. Decisions
+// are cached by the expanded roles, including group roles, and scope of the
+// subject, so a change to any of them is never served a stale decision.
+// Only allowed and unauthorized decisions are cached, other errors are
+// returned without caching. Expired decisions are swept every ttl.
+// Prepare is not cached.
+func WithCache(auth Authorizer, ttl time.Duration) Authorizer {
+	return &cachedAuthorizer{
+		auth:     auth,
+		ttl:      ttl,
+		subjects: make(map[string]map[string]cachedDecision),
+	}
+}
+
+type cachedAuthorizer struct {
+	auth Authorizer
+	ttl  time.Duration
+
+	mu sync.Mutex
+	// subjects are the cached decisions keyed by subject ID, then by
+	// decisionKey.
+	subjects  map[string]map[string]cachedDecision
+	lastSweep time.Time
+}
+
+type cachedDecision struct {
+	err     error
+	expires time.Time
+}
+
+var (
+	_ Authorizer         = (*cachedAuthorizer)(nil)
+	_ SubjectInvalidator = (*cachedAuthorizer)(nil)
+)
+
+func (c *cachedAuthorizer) Authorize(ctx context.Context, subject Subject, action Action, object Object) error {
+	key, err := decisionKey(subject, action, object)
+	if err != nil {
+		// The roles can't be expanded, which the authorizer reports.
+		return c.auth.Authorize(ctx, subject, action, object)
+	}
+
+	c.mu.Lock()
+	decision, ok := c.subjects[subject.ID][key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(decision.expires) {
+		return decision.err
+	}
+
+	err = c.auth.Authorize(ctx, subject, action, object)
+	var unauthorized *UnauthorizedError
+	if err != nil && !xerrors.As(err, &unauthorized) {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= c.ttl {
+		c.sweep(now)
+	}
+	decisions, ok := c.subjects[subject.ID]
+	if !ok {
+		decisions = make(map[string]cachedDecision)
+		c.subjects[subject.ID] = decisions
+	}
+	decisions[key] = cachedDecision{
+		err:     err,
+		expires: now.Add(c.ttl),
+	}
+	return err
+}
+
+// sweep removes the expired decisions. c.mu must be held.
+func (c *cachedAuthorizer) sweep(now time.Time) {
+	c.lastSweep = now
+	for subjectID, decisions := range c.subjects {
+		for key, decision := range decisions {
+			if !now.Before(decision.expires) {
+				delete(decisions, key)
+			}
+		}
+		if len(decisions) == 0 {
+			delete(c.subjects, subjectID)
+		}
+	}
+}
+
+// size returns the number of cached decisions.
+func (c *cachedAuthorizer) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var size int
+	for _, decisions := range c.subjects {
+		size += len(decisions)
+	}
+	return size
+}
+
+func (c *cachedAuthorizer) Prepare(ctx context.Context, subject Subject, action Action, objectType string) (PreparedAuthorized, error) {
+	return c.auth.Prepare(ctx, subject, action, objectType)
+}
+
+func (c *cachedAuthorizer) InvalidateSubject(subjectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.subjects, subjectID)
+}
+
+// decisionKey identifies a decision for a subject. It includes a hash of
+// everything the decision depends on: the expanded roles, so changes to a
+// role or a group's roles are not served a stale decision, the scope, the
+// groups, the action and the object.
+func decisionKey(subject Subject, action Action, object Object) (string, error) {
+	roles, err := subject.expandRoles()
+	if err != nil {
+		return "", err
+	}
+	var scope *Scope
+	if subject.Scope != nil {
+		expanded, err := subject.Scope.Expand()
+		if err != nil {
+			return "", err
+		}
+		scope = &expanded
+	}
+	groups := append([]string{}, subject.Groups...)
+	sort.Strings(groups)
+
+	data, err := json.Marshal(struct {
+		Roles  []Role   `json:"roles"`
+		Scope  *Scope   `json:"scope"`
+		Groups []string `json:"groups"`
+		Action Action   `json:"action"`
+		Object string   `json:"object"`
+	}{
+		Roles:  roles,
+		Scope:  scope,
+		Groups: groups,
+		Action: action,
+		Object: object.CacheKey(),
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
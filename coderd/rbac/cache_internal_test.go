@@ -0,0 +1,68 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/testutil"
+)
+
+func TestCacheInvalidateWrapped(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	cache := WithCache(NewAuthorizer(prometheus.NewRegistry()), time.Hour).(*cachedAuthorizer)
+	var auth Authorizer = cache
+	auth = WithKnownResources(auth)
+	auth = WithDenialMetrics(auth, prometheus.NewRegistry())
+	auth = WithRateLimit(auth, slogtest.Make(t, nil), 1000, 1000)
+	auth = WithTimeout(auth, time.Minute)
+
+	subject := Subject{
+		ID:    uuid.NewString(),
+		Roles: RoleNames{RoleMember()},
+		Scope: ScopeAll,
+	}
+	err := auth.Authorize(ctx, subject, ActionRead, ResourceWorkspace.WithOwner(subject.ID))
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.size())
+
+	// The wrappers forward the invalidation to the cache.
+	InvalidateSubject(auth, subject.ID)
+	require.Equal(t, 0, cache.size())
+}
+
+func TestCacheSweep(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	const ttl = 10 * time.Millisecond
+	cache := WithCache(NewAuthorizer(prometheus.NewRegistry()), ttl).(*cachedAuthorizer)
+	subject := Subject{
+		ID:    uuid.NewString(),
+		Roles: RoleNames{RoleMember()},
+		Scope: ScopeAll,
+	}
+	err := cache.Authorize(ctx, subject, ActionRead, ResourceWorkspace.WithOwner(subject.ID))
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.size())
+
+	time.Sleep(2 * ttl)
+	// Caching another decision sweeps the expired one.
+	other := subject
+	other.ID = uuid.NewString()
+	err = cache.Authorize(ctx, other, ActionRead, ResourceWorkspace.WithOwner(other.ID))
+	require.NoError(t, err)
+	require.Equal(t, 1, cache.size())
+}
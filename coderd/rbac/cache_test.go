@@ -0,0 +1,57 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWithCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	auth := rbac.WithCache(rbac.NewAuthorizer(prometheus.NewRegistry()), time.Hour)
+	admin := rbac.Subject{
+		ID:    uuid.NewString(),
+		Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleTemplateAdmin()},
+		Scope: rbac.ScopeAll,
+	}
+	template := rbac.ResourceTemplate.InOrg(uuid.New())
+
+	err := auth.Authorize(ctx, admin, rbac.ActionUpdate, template)
+	require.NoError(t, err)
+
+	// Decisions are cached by roles, so a revoked role takes effect
+	// immediately.
+	revoked := admin
+	revoked.Roles = rbac.RoleNames{rbac.RoleMember()}
+	err = auth.Authorize(ctx, revoked, rbac.ActionUpdate, template)
+	require.Error(t, err)
+	var uerr *rbac.UnauthorizedError
+	require.ErrorAs(t, err, &uerr)
+
+	// So does a role granted through a group.
+	group := uuid.NewString()
+	grouped := revoked
+	grouped.Groups = []string{group}
+	grouped.GroupRoles = map[string]rbac.ExpandableRoles{
+		group: rbac.RoleNames{rbac.RoleTemplateAdmin()},
+	}
+	err = auth.Authorize(ctx, grouped, rbac.ActionUpdate, template)
+	require.NoError(t, err)
+	grouped.GroupRoles = nil
+	err = auth.Authorize(ctx, grouped, rbac.ActionUpdate, template)
+	require.ErrorAs(t, err, &uerr)
+
+	// Invalidating an authorizer without a cache is a noop.
+	rbac.InvalidateSubject(rbac.NewAuthorizer(prometheus.NewRegistry()), admin.ID)
+}
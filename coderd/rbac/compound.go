@@ -0,0 +1,88 @@
+package rbac
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// AuthorizeMove authorizes moving an object from its current organization to
+// a new organization. Moving is treated as deleting the object from the source
+// organization and creating it in the destination organization, so the
+// subject must be allowed both.
+func AuthorizeMove(ctx context.Context, auth Authorizer, subject Subject, object Object, newOrgID uuid.UUID) error {
+	err := auth.Authorize(ctx, subject, ActionDelete, object)
+	if err != nil {
+		return xerrors.Errorf("source organization: %w", err)
+	}
+
+	err = auth.Authorize(ctx, subject, ActionCreate, object.InOrg(newOrgID))
+	if err != nil {
+		return xerrors.Errorf("destination organization: %w", err)
+	}
+	return nil
+}
+
+// AuthorizeTransfer authorizes giving an object to a new owner. The subject
+// must be allowed to delete the object from its current owner, which the
+// owner and admins are. Subjects transferring an object they do not own must
+// also be allowed to create it for the new owner, so admins can only force
+// transfers to users within their reach, eg an organization admin within
+// their organization. The new owner is not otherwise checked, as their
+// roles are not known here.
+func AuthorizeTransfer(ctx context.Context, auth Authorizer, subject Subject, object Object, newOwnerID string) error {
+	if newOwnerID == "" {
+		return xerrors.New("new owner must be set")
+	}
+	if newOwnerID == object.Owner {
+		return xerrors.Errorf("%s is already owned by %q", object.Type, newOwnerID)
+	}
+
+	err := auth.Authorize(ctx, subject, ActionDelete, object)
+	if err != nil {
+		return xerrors.Errorf("current owner: %w", err)
+	}
+	if subject.ID == object.Owner {
+		return nil
+	}
+
+	err = auth.Authorize(ctx, subject, ActionCreate, object.WithOwner(newOwnerID))
+	if err != nil {
+		return xerrors.Errorf("new owner: %w", err)
+	}
+	return nil
+}
+
+// ErrQuotaExceeded is returned by AuthorizeCreate when the subject is allowed
+// to create the object, but doing so would exceed a quota.
+var ErrQuotaExceeded = xerrors.New("quota exceeded")
+
+// QuotaChecker reports whether creating the object is within quota. Quotas
+// are not part of RBAC, so the checker is provided by the caller, eg to limit
+// the number of workspaces in an organization.
+type QuotaChecker func(ctx context.Context, subject Subject, object Object) (bool, error)
+
+// AuthorizeCreate authorizes creating an object, then checks the quota if a
+// checker is provided. Permission is checked first so subjects that are not
+// allowed to create the object do not learn about the quota. A forbidden
+// create returns an *UnauthorizedError, while an exceeded quota returns an
+// error wrapping ErrQuotaExceeded.
+func AuthorizeCreate(ctx context.Context, auth Authorizer, subject Subject, object Object, quota QuotaChecker) error {
+	err := auth.Authorize(ctx, subject, ActionCreate, object)
+	if err != nil {
+		return err
+	}
+	if quota == nil {
+		return nil
+	}
+
+	ok, err := quota(ctx, subject, object)
+	if err != nil {
+		return xerrors.Errorf("check quota: %w", err)
+	}
+	if !ok {
+		return xerrors.Errorf("create %s: %w", object.Type, ErrQuotaExceeded)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestAuthorizeMove(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	source := uuid.New()
+	destination := uuid.New()
+	workspace := rbac.ResourceWorkspace.WithID(uuid.New()).InOrg(source).WithOwner("other")
+
+	subject := func(roles ...string) rbac.Subject {
+		return rbac.Subject{
+			ID:    uuid.NewString(),
+			Roles: rbac.RoleNames(append(roles, rbac.RoleMember())),
+			Scope: rbac.ScopeAll,
+		}
+	}
+
+	testCases := []struct {
+		Name    string
+		Subject rbac.Subject
+		Error   string
+	}{
+		{
+			Name:    "AllowedInBoth",
+			Subject: subject(rbac.RoleOrgAdmin(source), rbac.RoleOrgAdmin(destination)),
+		},
+		{
+			Name:    "DeniedInSource",
+			Subject: subject(rbac.RoleOrgMember(source), rbac.RoleOrgAdmin(destination)),
+			Error:   "source organization",
+		},
+		{
+			Name:    "DeniedInDestination",
+			Subject: subject(rbac.RoleOrgAdmin(source), rbac.RoleOrgMember(destination)),
+			Error:   "destination organization",
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+			defer cancel()
+
+			err := rbac.AuthorizeMove(ctx, auth, c.Subject, workspace, destination)
+			if c.Error == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, c.Error)
+			var uerr *rbac.UnauthorizedError
+			require.True(t, xerrors.As(err, &uerr), "unauthorized error")
+		})
+	}
+}
+
+func TestAuthorizeTransfer(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	orgID := uuid.New()
+	ownerID := uuid.NewString()
+	newOwnerID := uuid.NewString()
+	workspace := rbac.ResourceWorkspace.WithID(uuid.New()).InOrg(orgID).WithOwner(ownerID)
+
+	subject := func(id string, roles ...string) rbac.Subject {
+		return rbac.Subject{
+			ID:    id,
+			Roles: rbac.RoleNames(append(roles, rbac.RoleMember())),
+			Scope: rbac.ScopeAll,
+		}
+	}
+
+	testCases := []struct {
+		Name         string
+		Subject      rbac.Subject
+		NewOwner     string
+		Unauthorized bool
+		Error        string
+	}{
+		{
+			Name:     "Owner",
+			Subject:  subject(ownerID, rbac.RoleOrgMember(orgID)),
+			NewOwner: newOwnerID,
+		},
+		{
+			Name:         "NotOwner",
+			Subject:      subject(uuid.NewString(), rbac.RoleOrgMember(orgID)),
+			NewOwner:     newOwnerID,
+			Unauthorized: true,
+			Error:        "current owner",
+		},
+		{
+			Name:         "NewOwnerTakes",
+			Subject:      subject(newOwnerID, rbac.RoleOrgMember(orgID)),
+			NewOwner:     newOwnerID,
+			Unauthorized: true,
+			Error:        "current owner",
+		},
+		{
+			Name:     "OrgAdmin",
+			Subject:  subject(uuid.NewString(), rbac.RoleOrgAdmin(orgID)),
+			NewOwner: newOwnerID,
+		},
+		{
+			Name:     "SiteAdmin",
+			Subject:  subject(uuid.NewString(), rbac.RoleOwner()),
+			NewOwner: newOwnerID,
+		},
+		{
+			Name:     "SameOwner",
+			Subject:  subject(ownerID, rbac.RoleOrgMember(orgID)),
+			NewOwner: ownerID,
+			Error:    "already owned",
+		},
+		{
+			Name:    "NoNewOwner",
+			Subject: subject(ownerID, rbac.RoleOrgMember(orgID)),
+			Error:   "new owner must be set",
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+			defer cancel()
+
+			err := rbac.AuthorizeTransfer(ctx, auth, c.Subject, workspace, c.NewOwner)
+			if c.Error == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, c.Error)
+			var uerr *rbac.UnauthorizedError
+			require.Equal(t, c.Unauthorized, xerrors.As(err, &uerr), "unauthorized error")
+		})
+	}
+}
+
+func TestAuthorizeCreate(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	orgID := uuid.New()
+	workspace := rbac.ResourceWorkspace.InOrg(orgID).WithOwner("me")
+
+	member := rbac.Subject{
+		ID:    "me",
+		Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgID)},
+		Scope: rbac.ScopeAll,
+	}
+	outsider := rbac.Subject{
+		ID:    "me",
+		Roles: rbac.RoleNames{rbac.RoleMember()},
+		Scope: rbac.ScopeAll,
+	}
+	quota := func(allowed bool) rbac.QuotaChecker {
+		return func(context.Context, rbac.Subject, rbac.Object) (bool, error) {
+			return allowed, nil
+		}
+	}
+
+	t.Run("NoQuota", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err := rbac.AuthorizeCreate(ctx, auth, member, workspace, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("WithinQuota", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err := rbac.AuthorizeCreate(ctx, auth, member, workspace, quota(true))
+		require.NoError(t, err)
+	})
+
+	t.Run("OverQuota", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err := rbac.AuthorizeCreate(ctx, auth, member, workspace, quota(false))
+		require.ErrorIs(t, err, rbac.ErrQuotaExceeded)
+		var uerr *rbac.UnauthorizedError
+		require.False(t, xerrors.As(err, &uerr), "not an unauthorized error")
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		checked := false
+		err := rbac.AuthorizeCreate(ctx, auth, outsider, workspace, func(context.Context, rbac.Subject, rbac.Object) (bool, error) {
+			checked = true
+			return true, nil
+		})
+		var uerr *rbac.UnauthorizedError
+		require.True(t, xerrors.As(err, &uerr), "unauthorized error")
+		require.NotErrorIs(t, err, rbac.ErrQuotaExceeded)
+		require.False(t, checked, "quota not checked")
+	})
+}
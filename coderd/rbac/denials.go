@@ -0,0 +1,86 @@
+package rbac
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/xerrors"
+)
+
+// WithDenialMetrics counts the objects denied by the authorizer by
+// resource type, action and endpoint. The endpoint is the chi route pattern
+// of the request, or empty outside of a request. Use this to find endpoints
+// and resources that are denied most often, such as an over-restrictive
+// policy. Only unauthorized errors are counted, not other failures.
+func WithDenialMetrics(auth Authorizer, registry prometheus.Registerer) Authorizer {
+	factory := promauto.With(registry)
+	return &denialMetricsAuthorizer{
+		auth: auth,
+		denials: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "authz",
+			Name:      "denials_total",
+			Help:      "The total number of denied objects by resource type, action and endpoint.",
+		}, []string{"type", "action", "endpoint"}),
+	}
+}
+
+type denialMetricsAuthorizer struct {
+	auth    Authorizer
+	denials *prometheus.CounterVec
+}
+
+var (
+	_ Authorizer         = (*denialMetricsAuthorizer)(nil)
+	_ SubjectInvalidator = (*denialMetricsAuthorizer)(nil)
+)
+
+func (d *denialMetricsAuthorizer) Authorize(ctx context.Context, subject Subject, action Action, object Object) error {
+	err := d.auth.Authorize(ctx, subject, action, object)
+	d.observe(ctx, err, action, object.Type)
+	return err
+}
+
+func (d *denialMetricsAuthorizer) Prepare(ctx context.Context, subject Subject, action Action, objectType string) (PreparedAuthorized, error) {
+	prepared, err := d.auth.Prepare(ctx, subject, action, objectType)
+	if err != nil {
+		return nil, err
+	}
+	return &denialMetricsPrepared{
+		PreparedAuthorized: prepared,
+		metrics:            d,
+		action:             action,
+	}, nil
+}
+
+func (d *denialMetricsAuthorizer) observe(ctx context.Context, err error, action Action, objectType string) {
+	var unauthorized *UnauthorizedError
+	if err == nil || !xerrors.As(err, &unauthorized) {
+		return
+	}
+	endpoint := ""
+	if routeCtx := chi.RouteContext(ctx); routeCtx != nil {
+		endpoint = routeCtx.RoutePattern()
+	}
+	d.denials.WithLabelValues(objectType, string(action), endpoint).Inc()
+}
+
+// denialMetricsPrepared counts the denials of objects filtered in Go, eg
+// by Filter.
+type denialMetricsPrepared struct {
+	PreparedAuthorized
+	metrics *denialMetricsAuthorizer
+	action  Action
+}
+
+func (d *denialMetricsPrepared) Authorize(ctx context.Context, object Object) error {
+	err := d.PreparedAuthorized.Authorize(ctx, object)
+	d.metrics.observe(ctx, err, d.action, object.Type)
+	return err
+}
+
+func (d *denialMetricsAuthorizer) InvalidateSubject(subjectID string) {
+	InvalidateSubject(d.auth, subjectID)
+}
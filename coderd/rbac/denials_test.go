@@ -0,0 +1,52 @@
+package rbac_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWithDenialMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	auth := rbac.WithDenialMetrics(rbac.NewAuthorizer(prometheus.NewRegistry()), registry)
+	subject := rbac.Subject{
+		ID:    uuid.NewString(),
+		Roles: rbac.RoleNames{rbac.RoleMember()},
+		Scope: rbac.ScopeAll,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	// Allowed.
+	require.NoError(t, auth.Authorize(ctx, subject, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner(subject.ID)))
+	// Denied.
+	require.Error(t, auth.Authorize(ctx, subject, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner("other")))
+	require.Error(t, auth.Authorize(ctx, subject, rbac.ActionDelete, rbac.ResourceWorkspace.WithOwner("other")))
+	require.Error(t, auth.Authorize(ctx, subject, rbac.ActionRead, rbac.ResourceDeploymentConfig))
+	require.Error(t, auth.Authorize(ctx, subject, rbac.ActionRead, rbac.ResourceDeploymentConfig))
+
+	prepared, err := auth.Prepare(ctx, subject, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+	require.NoError(t, err)
+	require.Error(t, prepared.Authorize(ctx, rbac.ResourceWorkspace.WithOwner("other")))
+
+	const expected = `
+# HELP coderd_authz_denials_total The total number of denied objects by resource type, action and endpoint.
+# TYPE coderd_authz_denials_total counter
+coderd_authz_denials_total{action="delete",endpoint="",type="workspace"} 1
+coderd_authz_denials_total{action="read",endpoint="",type="deployment_config"} 2
+coderd_authz_denials_total{action="read",endpoint="",type="workspace"} 2
+`
+	err = promtestutil.GatherAndCompare(registry, strings.NewReader(expected), "coderd_authz_denials_total")
+	require.NoError(t, err)
+}
@@ -1,6 +1,10 @@
 package rbac
 
-import "github.com/open-policy-agent/opa/rego"
+import (
+	"sync"
+
+	"github.com/open-policy-agent/opa/rego"
+)
 
 const (
 	// errUnauthorized is the error message that should be returned to
@@ -9,6 +13,32 @@ const (
 	errUnauthorized = "forbidden"
 )
 
+// DenyReason is a coarse reason for a denied authorization. Reasons are
+// safe to show to clients, as they do not disclose the policy.
+type DenyReason string
+
+const (
+	// DenyReasonUnknown is used when the reason could not be determined.
+	DenyReasonUnknown DenyReason = "unknown"
+	// DenyReasonInsufficientRole means no role or ACL allows the action.
+	DenyReasonInsufficientRole DenyReason = "insufficient_role"
+	// DenyReasonOutOfScope means a role allows the action, but the scope of
+	// the subject does not.
+	DenyReasonOutOfScope DenyReason = "out_of_scope"
+)
+
+// Message is a user facing message for the reason.
+func (r DenyReason) Message() string {
+	switch r {
+	case DenyReasonInsufficientRole:
+		return "You do not have a role that allows this action."
+	case DenyReasonOutOfScope:
+		return "The scope of your credentials does not allow this action."
+	default:
+		return "Forbidden."
+	}
+}
+
 // UnauthorizedError is the error type for authorization errors
 type UnauthorizedError struct {
 	// internal is the internal error that should never be shown to the client.
@@ -16,6 +46,17 @@ type UnauthorizedError struct {
 	internal error
 	input    map[string]interface{}
 	output   rego.ResultSet
+	reason   DenyReason
+	// lazyReason finds the reason when it is first needed, as finding it
+	// evaluates the policy again.
+	lazyReason *lazyReason
+}
+
+// lazyReason computes a deny reason once.
+type lazyReason struct {
+	once   sync.Once
+	fn     func() DenyReason
+	reason DenyReason
 }
 
 // ForbiddenWithInternal creates a new error that will return a simple
@@ -37,6 +78,35 @@ func (UnauthorizedError) Error() string {
 	return errUnauthorized
 }
 
+// withReason sets the reason the authorization was denied.
+func (e *UnauthorizedError) withReason(reason DenyReason) *UnauthorizedError {
+	e.reason = reason
+	return e
+}
+
+// withLazyReason sets a function that finds the reason the authorization
+// was denied. It is called the first time Reason is, so denials that are
+// never explained, eg objects filtered out of a list, don't pay for it.
+func (e *UnauthorizedError) withLazyReason(fn func() DenyReason) *UnauthorizedError {
+	e.lazyReason = &lazyReason{fn: fn}
+	return e
+}
+
+// Reason is a coarse reason for the denial that is safe to show to clients.
+func (e *UnauthorizedError) Reason() DenyReason {
+	reason := e.reason
+	if reason == "" && e.lazyReason != nil {
+		e.lazyReason.once.Do(func() {
+			e.lazyReason.reason = e.lazyReason.fn()
+		})
+		reason = e.lazyReason.reason
+	}
+	if reason == "" {
+		return DenyReasonUnknown
+	}
+	return reason
+}
+
 // Internal allows the internal error message to be logged.
 func (e *UnauthorizedError) Internal() error {
 	return e.internal
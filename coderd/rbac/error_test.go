@@ -0,0 +1,74 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+func TestDenyReason(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	me := uuid.NewString()
+	orgID := uuid.New()
+	workspace := rbac.ResourceWorkspace.WithID(uuid.New()).InOrg(orgID)
+
+	testCases := []struct {
+		Name    string
+		Subject rbac.Subject
+		Object  rbac.Object
+		Reason  rbac.DenyReason
+	}{
+		{
+			Name: "Role",
+			Subject: rbac.Subject{
+				ID:    me,
+				Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgID)},
+				Scope: rbac.ScopeAll,
+			},
+			Object: workspace.WithOwner(uuid.NewString()),
+			Reason: rbac.DenyReasonInsufficientRole,
+		},
+		{
+			Name: "Scope",
+			Subject: rbac.Subject{
+				ID:    me,
+				Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgID)},
+				Scope: rbac.ScopeApplicationConnect,
+			},
+			Object: workspace.WithOwner(me),
+			Reason: rbac.DenyReasonOutOfScope,
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			err := auth.Authorize(ctx, c.Subject, rbac.ActionRead, c.Object)
+			requireReason(t, c.Reason, err)
+
+			prepared, err := auth.Prepare(ctx, c.Subject, rbac.ActionRead, c.Object.Type)
+			require.NoError(t, err)
+			err = prepared.Authorize(ctx, c.Object)
+			requireReason(t, c.Reason, err)
+		})
+	}
+}
+
+func requireReason(t *testing.T, expected rbac.DenyReason, err error) {
+	t.Helper()
+	var unauthorized *rbac.UnauthorizedError
+	require.True(t, xerrors.As(err, &unauthorized), "expected an unauthorized error, got %v", err)
+	require.Equal(t, expected, unauthorized.Reason())
+	require.NotEqual(t, rbac.DenyReasonUnknown.Message(), unauthorized.Reason().Message())
+}
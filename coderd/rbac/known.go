@@ -0,0 +1,64 @@
+package rbac
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrUnknownResource is returned by WithKnownResources when the object's
+// resource type is not one of AllResources.
+var ErrUnknownResource = xerrors.New("unknown resource type")
+
+// WithKnownResources returns an error wrapping ErrUnknownResource instead of
+// authorizing objects with a resource type that is not registered in
+// AllResources. Unknown types are always denied by the policy, this makes it
+// possible to tell a missing resource from a denied one, such as when a new
+// resource is not yet added to the roles.
+func WithKnownResources(auth Authorizer) Authorizer {
+	known := make(map[string]bool)
+	for _, resource := range AllResources() {
+		known[resource.Type] = true
+	}
+	return &knownResourcesAuthorizer{
+		auth:  auth,
+		known: known,
+	}
+}
+
+type knownResourcesAuthorizer struct {
+	auth  Authorizer
+	known map[string]bool
+}
+
+var (
+	_ Authorizer         = (*knownResourcesAuthorizer)(nil)
+	_ SubjectInvalidator = (*knownResourcesAuthorizer)(nil)
+)
+
+func (k *knownResourcesAuthorizer) Authorize(ctx context.Context, subject Subject, action Action, object Object) error {
+	err := k.check(object.Type)
+	if err != nil {
+		return err
+	}
+	return k.auth.Authorize(ctx, subject, action, object)
+}
+
+func (k *knownResourcesAuthorizer) Prepare(ctx context.Context, subject Subject, action Action, objectType string) (PreparedAuthorized, error) {
+	err := k.check(objectType)
+	if err != nil {
+		return nil, err
+	}
+	return k.auth.Prepare(ctx, subject, action, objectType)
+}
+
+func (k *knownResourcesAuthorizer) check(objectType string) error {
+	if !k.known[objectType] {
+		return xerrors.Errorf("%q: %w", objectType, ErrUnknownResource)
+	}
+	return nil
+}
+
+func (k *knownResourcesAuthorizer) InvalidateSubject(subjectID string) {
+	InvalidateSubject(k.auth, subjectID)
+}
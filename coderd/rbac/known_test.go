@@ -0,0 +1,67 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWithKnownResources(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.WithKnownResources(rbac.NewAuthorizer(prometheus.NewRegistry()))
+	subject := rbac.Subject{
+		ID:    uuid.NewString(),
+		Roles: rbac.RoleNames{rbac.RoleOwner()},
+		Scope: rbac.ScopeAll,
+	}
+	unknown := rbac.Object{Type: "not_a_resource"}
+
+	t.Run("Unknown", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err := auth.Authorize(ctx, subject, rbac.ActionRead, unknown)
+		require.ErrorIs(t, err, rbac.ErrUnknownResource)
+		var uerr *rbac.UnauthorizedError
+		require.False(t, xerrors.As(err, &uerr), "not an unauthorized error")
+
+		_, err = auth.Prepare(ctx, subject, rbac.ActionRead, unknown.Type)
+		require.ErrorIs(t, err, rbac.ErrUnknownResource)
+	})
+
+	t.Run("Known", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		for _, resource := range rbac.AllResources() {
+			err := auth.Authorize(ctx, subject, rbac.ActionRead, resource)
+			require.NoError(t, err, resource.Type)
+		}
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		member := rbac.Subject{
+			ID:    uuid.NewString(),
+			Roles: rbac.RoleNames{rbac.RoleMember()},
+			Scope: rbac.ScopeAll,
+		}
+		err := auth.Authorize(ctx, member, rbac.ActionRead, rbac.ResourceDeploymentConfig)
+		var uerr *rbac.UnauthorizedError
+		require.True(t, xerrors.As(err, &uerr), "unauthorized error")
+		require.NotErrorIs(t, err, rbac.ErrUnknownResource)
+	})
+}
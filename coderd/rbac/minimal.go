@@ -0,0 +1,89 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// MinimalRolesFor returns the names of the built-in roles that grant the
+// action on the object, sorted by breadth with the narrowest role first.
+// Organization roles are included if the object is in an organization. Use
+// this to find the least privileged role to assign, instead of the owner
+// role.
+//
+// Roles are evaluated on their own, for a subject that owns the object if
+// the object has an owner.
+func MinimalRolesFor(ctx context.Context, auth Authorizer, action Action, object Object) ([]string, error) {
+	roles := SiteRoles()
+	if orgID, err := uuid.Parse(object.OrgID); err == nil {
+		roles = append(roles, OrganizationRoles(orgID)...)
+	}
+
+	subjectID := object.Owner
+	if subjectID == "" {
+		subjectID = uuid.NewString()
+	}
+
+	var granted []Role
+	for _, role := range roles {
+		err := auth.Authorize(ctx, Subject{
+			ID:    subjectID,
+			Roles: Roles{role},
+			Scope: ScopeAll,
+		}, action, object)
+		if err == nil {
+			granted = append(granted, role)
+			continue
+		}
+		var unauthorized *UnauthorizedError
+		if !xerrors.As(err, &unauthorized) {
+			return nil, xerrors.Errorf("authorize role %q: %w", role.Name, err)
+		}
+	}
+
+	sort.Slice(granted, func(i, j int) bool {
+		bi, bj := roleBreadth(granted[i]), roleBreadth(granted[j])
+		for level := range bi {
+			if bi[level] != bj[level] {
+				return bi[level] < bj[level]
+			}
+		}
+		return granted[i].Name < granted[j].Name
+	})
+	names := make([]string, 0, len(granted))
+	for _, role := range granted {
+		names = append(names, role.Name)
+	}
+	return names, nil
+}
+
+// roleBreadth is the number of resource and action pairs the role grants
+// at the site, org and user level. Wildcards count every resource or
+// action. Site permissions apply everywhere, so they are compared first.
+func roleBreadth(role Role) [3]int {
+	count := func(perms []Permission) int {
+		breadth := 0
+		for _, perm := range perms {
+			if perm.Negate {
+				continue
+			}
+			resources, actions := 1, 1
+			if perm.ResourceType == WildcardSymbol {
+				resources = len(AllResources())
+			}
+			if perm.Action == WildcardSymbol {
+				actions = len(AllActions())
+			}
+			breadth += resources * actions
+		}
+		return breadth
+	}
+	org := 0
+	for _, perms := range role.Org {
+		org += count(perms)
+	}
+	return [3]int{count(role.Site), org, count(role.User)}
+}
@@ -0,0 +1,52 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMinimalRolesFor(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+
+	t.Run("Narrow", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		roles, err := rbac.MinimalRolesFor(ctx, auth, rbac.ActionRead, rbac.ResourceAuditLog)
+		require.NoError(t, err)
+		require.Equal(t, []string{"auditor", rbac.RoleOwner()}, roles)
+	})
+
+	t.Run("Broad", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		roles, err := rbac.MinimalRolesFor(ctx, auth, rbac.ActionUpdate, rbac.ResourceDeploymentConfig)
+		require.NoError(t, err)
+		require.Equal(t, []string{rbac.RoleOwner()}, roles)
+	})
+
+	t.Run("Organization", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		orgID := uuid.New()
+		roles, err := rbac.MinimalRolesFor(ctx, auth, rbac.ActionUpdate, rbac.ResourceTemplate.InOrg(orgID))
+		require.NoError(t, err)
+		require.Contains(t, roles, rbac.RoleOrgAdmin(orgID))
+		require.Contains(t, roles, rbac.RoleTemplateAdmin())
+		require.Equal(t, rbac.RoleOwner(), roles[len(roles)-1], "owner is the broadest")
+	})
+}
@@ -1,6 +1,14 @@
 package rbac
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/google/uuid"
 )
 
@@ -54,6 +62,16 @@ var (
 		Type: "template",
 	}
 
+	// ResourceTemplateVersion allows managing the versions of a template
+	// without full control of the template. Org owner only.
+	//	create = Upload a new version
+	//	read = Read a version
+	//	update = Promote a version to be the active version
+	//	delete = Archive a version
+	ResourceTemplateVersion = Object{
+		Type: "template_version",
+	}
+
 	// ResourceGroup CRUD. Org admins only.
 	//	create/delete = Make or delete a new group.
 	//	update = Update the name or members of a group.
@@ -157,6 +175,34 @@ var (
 	}
 )
 
+// AllResources returns every resource type. New resources must be added here
+// to be accepted by WithKnownResources.
+func AllResources() []Object {
+	return []Object{
+		ResourceWorkspace,
+		ResourceWorkspaceExecution,
+		ResourceWorkspaceApplicationConnect,
+		ResourceAuditLog,
+		ResourceTemplate,
+		ResourceTemplateVersion,
+		ResourceGroup,
+		ResourceFile,
+		ResourceProvisionerDaemon,
+		ResourceOrganization,
+		ResourceRoleAssignment,
+		ResourceOrgRoleAssignment,
+		ResourceAPIKey,
+		ResourceUser,
+		ResourceUserData,
+		ResourceOrganizationMember,
+		ResourceWildcard,
+		ResourceLicense,
+		ResourceDeploymentConfig,
+		ResourceReplicas,
+		ResourceDebugInfo,
+	}
+}
+
 // Object is used to create objects for authz checks when you have none in
 // hand to run the check on.
 // An example is if you want to list all workspaces, you can create a Object
@@ -174,6 +220,11 @@ type Object struct {
 
 	ACLUserList  map[string][]Action ` json:"acl_user_list"`
 	ACLGroupList map[string][]Action ` json:"acl_group_list"`
+
+	// Parent is the resource the object is a sub-resource of, eg the
+	// workspace of a workspace agent. Permissions on the parent apply to
+	// the object, see WithParent. It is not passed to the policy.
+	Parent *Object `json:"-"`
 }
 
 func (z Object) RBACObject() Object {
@@ -199,6 +250,7 @@ func (z Object) WithIDString(id string) Object {
 		Type:         z.Type,
 		ACLUserList:  z.ACLUserList,
 		ACLGroupList: z.ACLGroupList,
+		Parent:       z.Parent,
 	}
 }
 
@@ -210,6 +262,7 @@ func (z Object) WithID(id uuid.UUID) Object {
 		Type:         z.Type,
 		ACLUserList:  z.ACLUserList,
 		ACLGroupList: z.ACLGroupList,
+		Parent:       z.Parent,
 	}
 }
 
@@ -222,6 +275,7 @@ func (z Object) InOrg(orgID uuid.UUID) Object {
 		Type:         z.Type,
 		ACLUserList:  z.ACLUserList,
 		ACLGroupList: z.ACLGroupList,
+		Parent:       z.Parent,
 	}
 }
 
@@ -234,6 +288,7 @@ func (z Object) WithOwner(ownerID string) Object {
 		Type:         z.Type,
 		ACLUserList:  z.ACLUserList,
 		ACLGroupList: z.ACLGroupList,
+		Parent:       z.Parent,
 	}
 }
 
@@ -246,6 +301,7 @@ func (z Object) WithACLUserList(acl map[string][]Action) Object {
 		Type:         z.Type,
 		ACLUserList:  acl,
 		ACLGroupList: z.ACLGroupList,
+		Parent:       z.Parent,
 	}
 }
 
@@ -257,5 +313,71 @@ func (z Object) WithGroupACL(groups map[string][]Action) Object {
 		Type:         z.Type,
 		ACLUserList:  z.ACLUserList,
 		ACLGroupList: groups,
+		Parent:       z.Parent,
+	}
+}
+
+// WithParent makes the object a sub-resource of the parent. Permissions are
+// checked from the most specific path to the least specific, and the first
+// path with an allowing or denying permission decides. A permission granted
+// on a workspace applies to its agents, unless the subject has a negated
+// permission on the agent.
+//
+// Prepared authorizers check parents the same way. SQL filters only know
+// about the row itself, so they cannot be used for sub-resources.
+func (z Object) WithParent(parent Object) Object {
+	z.Parent = &parent
+	return z
+}
+
+// Path is the path of the object through its parents, eg
+// "workspace/<id>/workspace_agent/<id>".
+func (z Object) Path() string {
+	segment := z.Type + "/" + z.ID
+	if z.Parent == nil {
+		return segment
+	}
+	return z.Parent.Path() + "/" + segment
+}
+
+// CacheKey returns a key that is equal for objects with the same
+// permission relevant fields, and differs if any of them differ. The ACLs
+// are hashed to keep the key short.
+func (z Object) CacheKey() string {
+	var key strings.Builder
+	for _, field := range []string{z.Type, z.ID, z.Owner, z.OrgID} {
+		// Quote the fields so they cannot be confused with the separator.
+		_, _ = key.WriteString(strconv.Quote(field))
+		_, _ = key.WriteRune('/')
+	}
+	if len(z.ACLUserList) > 0 || len(z.ACLGroupList) > 0 {
+		hash := sha256.New()
+		writeACL(hash, "user", z.ACLUserList)
+		writeACL(hash, "group", z.ACLGroupList)
+		_, _ = key.WriteRune('/')
+		_, _ = key.WriteString(hex.EncodeToString(hash.Sum(nil)[:16]))
+	}
+	if z.Parent != nil {
+		_, _ = key.WriteString("/parent:")
+		_, _ = key.WriteString(z.Parent.CacheKey())
+	}
+	return key.String()
+}
+
+// writeACL writes the ACL in a stable order. The order of the actions does
+// not affect permissions, so they are sorted too.
+func writeACL(w io.Writer, kind string, acl map[string][]Action) {
+	ids := make([]string, 0, len(acl))
+	for id := range acl {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		actions := make([]string, 0, len(acl[id]))
+		for _, action := range acl[id] {
+			actions = append(actions, string(action))
+		}
+		sort.Strings(actions)
+		_, _ = fmt.Fprintf(w, "%s %q %q\n", kind, id, actions)
 	}
 }
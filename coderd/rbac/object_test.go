@@ -0,0 +1,55 @@
+package rbac_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+func TestObjectCacheKey(t *testing.T) {
+	t.Parallel()
+
+	orgID := uuid.New()
+	object := func() rbac.Object {
+		return rbac.ResourceWorkspace.
+			WithID(uuid.MustParse("f9a5fb6a-1c5f-4b2a-a1a3-9b1a8f6a8d2b")).
+			InOrg(orgID).
+			WithOwner("owner").
+			WithACLUserList(map[string][]rbac.Action{
+				"alice": {rbac.ActionRead, rbac.ActionUpdate},
+				"bob":   {rbac.ActionRead},
+			})
+	}
+
+	t.Run("Equal", func(t *testing.T) {
+		t.Parallel()
+		require.Equal(t, object().CacheKey(), object().CacheKey())
+
+		// The order of actions does not matter.
+		reordered := object().WithACLUserList(map[string][]rbac.Action{
+			"bob":   {rbac.ActionRead},
+			"alice": {rbac.ActionUpdate, rbac.ActionRead},
+		})
+		require.Equal(t, object().CacheKey(), reordered.CacheKey())
+	})
+
+	t.Run("Different", func(t *testing.T) {
+		t.Parallel()
+		changed := map[string]rbac.Object{
+			"Type":     func() rbac.Object { o := object(); o.Type = rbac.ResourceTemplate.Type; return o }(),
+			"ID":       object().WithID(uuid.New()),
+			"Owner":    object().WithOwner("other"),
+			"Org":      object().InOrg(uuid.New()),
+			"UserACL":  object().WithACLUserList(map[string][]rbac.Action{"alice": {rbac.ActionRead}}),
+			"GroupACL": object().WithGroupACL(map[string][]rbac.Action{"group": {rbac.ActionRead}}),
+			// The separator in a field is not confused with the next field.
+			"Separator": func() rbac.Object { o := object(); o.Owner = `owner"/"` + o.OrgID; o.OrgID = ""; return o }(),
+		}
+		for name, o := range changed {
+			require.NotEqual(t, object().CacheKey(), o.CacheKey(), name)
+		}
+	})
+}
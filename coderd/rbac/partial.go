@@ -30,6 +30,9 @@ type PartialAuthorizer struct {
 
 var _ PreparedAuthorized = (*PartialAuthorizer)(nil)
 
+// CompileToSQL converts the partial queries of the prepared type to a SQL
+// filter. The filter only checks the row itself, as rows have no parents.
+// Sub-resources must be filtered with Filter or Authorize instead.
 func (pa *PartialAuthorizer) CompileToSQL(ctx context.Context, cfg regosql.ConvertConfig) (string, error) {
 	_, span := tracing.StartSpan(ctx, trace.WithAttributes(
 		// Query count is a rough indicator of the complexity of the query
@@ -47,6 +50,16 @@ func (pa *PartialAuthorizer) CompileToSQL(ctx context.Context, cfg regosql.Conve
 }
 
 func (pa *PartialAuthorizer) Authorize(ctx context.Context, object Object) error {
+	if object.Parent != nil {
+		// The partial queries only know about the prepared type, so the
+		// path is evaluated with the full policy, as Authorize does.
+		input := make(map[string]interface{}, len(pa.input))
+		for k, v := range pa.input {
+			input[k] = v
+		}
+		input["object"] = object
+		return authorizePath(ctx, input, object)
+	}
 	if pa.alwaysTrue {
 		return nil
 	}
@@ -54,7 +67,7 @@ func (pa *PartialAuthorizer) Authorize(ctx context.Context, object Object) error
 	// If we have no queries, then no queries can return 'true'.
 	// So the result is always 'false'.
 	if len(pa.preparedQueries) == 0 {
-		return ForbiddenWithInternal(xerrors.Errorf("policy disallows request"), pa.input, nil)
+		return ForbiddenWithInternal(xerrors.Errorf("policy disallows request"), pa.input, nil).withLazyReason(pa.denyReason(object))
 	}
 
 	parsed, err := ast.InterfaceToValue(map[string]interface{}{
@@ -118,7 +131,18 @@ EachQueryLoop:
 		return nil
 	}
 
-	return ForbiddenWithInternal(xerrors.Errorf("policy disallows request"), pa.input, nil)
+	return ForbiddenWithInternal(xerrors.Errorf("policy disallows request"), pa.input, nil).withLazyReason(pa.denyReason(object))
+}
+
+// denyReason returns a function that finds the reason the object was denied
+// using the full input.
+func (pa *PartialAuthorizer) denyReason(object Object) func() DenyReason {
+	input := make(map[string]interface{}, len(pa.input))
+	for k, v := range pa.input {
+		input[k] = v
+	}
+	input["object"] = object
+	return lazyDenyReason(input)
 }
 
 func newPartialAuthorizer(ctx context.Context, subject Subject, action Action, objectType string) (*PartialAuthorizer, error) {
@@ -129,7 +153,7 @@ func newPartialAuthorizer(ctx context.Context, subject Subject, action Action, o
 		return nil, xerrors.Errorf("subject must have a scope")
 	}
 
-	roles, err := subject.Roles.Expand()
+	roles, err := subject.expandRoles()
 	if err != nil {
 		return nil, xerrors.Errorf("expand roles: %w", err)
 	}
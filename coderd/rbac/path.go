@@ -0,0 +1,50 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+)
+
+// authorizePath authorizes an object with parents, starting at the object
+// and walking up to the root. The first object with a permission that
+// allows the action authorizes it, and the first object with a negated
+// permission denies it. This means a specific path deny overrides a
+// permission granted on a parent, eg a negated permission on an agent wins
+// over a permission on its workspace.
+func authorizePath(ctx context.Context, input map[string]interface{}, object Object) error {
+	path := object.Path()
+	for level := &object; level != nil; level = level.Parent {
+		levelInput := make(map[string]interface{}, len(input))
+		for k, v := range input {
+			levelInput[k] = v
+		}
+		levelInput["object"] = *level
+
+		rules, err := policyRules(ctx, levelInput)
+		if err != nil {
+			return ForbiddenWithInternal(xerrors.Errorf("eval rego: %w", err), levelInput, nil)
+		}
+		if rules["allow"] == true {
+			return nil
+		}
+		if deniesExplicitly(rules) {
+			return ForbiddenWithInternal(xerrors.Errorf("%s/%s in path %q denies request", level.Type, level.ID, path), levelInput, nil).withReason(DenyReasonInsufficientRole)
+		}
+	}
+	return ForbiddenWithInternal(xerrors.Errorf("policy disallows request for path %q", path), input, nil).withLazyReason(lazyDenyReason(input))
+}
+
+// deniesExplicitly returns true if a negated permission matched at the site,
+// org or user level. These rules are -1 for a negated permission, 0 when no
+// permission matched and 1 when allowed.
+func deniesExplicitly(rules map[string]interface{}) bool {
+	for _, rule := range []string{"site", "org", "user"} {
+		num, ok := rules[rule].(json.Number)
+		if ok && num.String() == "-1" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package rbac
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// ErrRateLimited is returned when a subject exceeds the authorization rate
+// limit.
+var ErrRateLimited = xerrors.New("authorization rate limit exceeded")
+
+// rateLimitSweepInterval is how often limiters of idle subjects are evicted.
+const rateLimitSweepInterval = time.Minute
+
+// WithRateLimit limits the rate of Authorize and Prepare calls for each
+// subject. This throttles runaway loops, such as N+1 authorization checks,
+// before they saturate the server. Calls above the limit return an error
+// wrapping ErrRateLimited. Checks of a PreparedAuthorized are not limited,
+// as those are used for batches of objects.
+func WithRateLimit(auth Authorizer, logger slog.Logger, limit rate.Limit, burst int) Authorizer {
+	return &rateLimitAuthorizer{
+		auth:     auth,
+		logger:   logger,
+		limit:    limit,
+		burst:    burst,
+		subjects: make(map[string]*subjectLimiter),
+		now:      time.Now,
+	}
+}
+
+type rateLimitAuthorizer struct {
+	auth   Authorizer
+	logger slog.Logger
+	limit  rate.Limit
+	burst  int
+
+	mu       sync.Mutex
+	subjects map[string]*subjectLimiter
+	// lastSweep is when idle limiters were last evicted.
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type subjectLimiter struct {
+	limiter *rate.Limiter
+	// lastUsed is when the subject was last checked.
+	lastUsed time.Time
+	// throttled is true while the subject is limited. It is used to only
+	// log the first limited call.
+	throttled bool
+}
+
+var (
+	_ Authorizer         = (*rateLimitAuthorizer)(nil)
+	_ SubjectInvalidator = (*rateLimitAuthorizer)(nil)
+)
+
+func (r *rateLimitAuthorizer) Authorize(ctx context.Context, subject Subject, action Action, object Object) error {
+	err := r.allow(ctx, subject)
+	if err != nil {
+		return err
+	}
+	return r.auth.Authorize(ctx, subject, action, object)
+}
+
+func (r *rateLimitAuthorizer) Prepare(ctx context.Context, subject Subject, action Action, objectType string) (PreparedAuthorized, error) {
+	err := r.allow(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	return r.auth.Prepare(ctx, subject, action, objectType)
+}
+
+func (r *rateLimitAuthorizer) allow(ctx context.Context, subject Subject) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.lastSweep) >= rateLimitSweepInterval {
+		r.sweep(now)
+	}
+
+	l, ok := r.subjects[subject.ID]
+	if !ok {
+		l = &subjectLimiter{limiter: rate.NewLimiter(r.limit, r.burst)}
+		r.subjects[subject.ID] = l
+	}
+	l.lastUsed = now
+	if l.limiter.AllowN(now, 1) {
+		l.throttled = false
+		return nil
+	}
+	if !l.throttled {
+		l.throttled = true
+		r.logger.Warn(ctx, "authorization checks throttled",
+			slog.F("subject_id", subject.ID),
+			slog.F("limit", float64(r.limit)),
+			slog.F("burst", r.burst),
+		)
+	}
+	return xerrors.Errorf("subject %q: %w", subject.ID, ErrRateLimited)
+}
+
+// sweep evicts the limiters of subjects that have been idle long enough for
+// their bucket to refill. A new limiter starts full, so evicting these does
+// not change what is allowed. The caller must hold the lock.
+func (r *rateLimitAuthorizer) sweep(now time.Time) {
+	r.lastSweep = now
+	if r.limit <= 0 {
+		// The bucket never refills, so evicting would reset the limit.
+		return
+	}
+	var refill time.Duration
+	if r.limit != rate.Inf {
+		refill = time.Duration(float64(r.burst) / float64(r.limit) * float64(time.Second))
+	}
+	for id, l := range r.subjects {
+		if now.Sub(l.lastUsed) >= refill {
+			delete(r.subjects, id)
+		}
+	}
+}
+
+func (r *rateLimitAuthorizer) InvalidateSubject(subjectID string) {
+	InvalidateSubject(r.auth, subjectID)
+}
@@ -0,0 +1,60 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"cdr.dev/slog/sloggers/slogtest"
+)
+
+func TestRateLimitEvictsIdleSubjects(t *testing.T) {
+	t.Parallel()
+
+	// The bucket of a subject refills from empty in two minutes.
+	auth := WithRateLimit(NewAuthorizer(prometheus.NewRegistry()),
+		slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), rate.Every(time.Minute), 2).(*rateLimitAuthorizer)
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	subject := func(id string) Subject {
+		return Subject{ID: id, Roles: RoleNames{RoleMember()}, Scope: ScopeAll}
+	}
+	allow := func(id string) error {
+		return auth.Authorize(ctx, subject(id), ActionRead, ResourceWorkspace.WithOwner(id))
+	}
+	limited := func() []string {
+		auth.mu.Lock()
+		defer auth.mu.Unlock()
+		ids := make([]string, 0, len(auth.subjects))
+		for id := range auth.subjects {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	require.NoError(t, allow("idle"))
+	require.NoError(t, allow("runaway"))
+	require.NoError(t, allow("runaway"))
+	require.ErrorIs(t, allow("runaway"), ErrRateLimited)
+
+	// The buckets have not refilled yet, so they are kept.
+	now = now.Add(rateLimitSweepInterval)
+	require.NoError(t, allow("active"))
+	require.ElementsMatch(t, []string{"idle", "runaway", "active"}, limited())
+
+	// Idle subjects are evicted once their buckets have refilled.
+	now = now.Add(rateLimitSweepInterval)
+	require.NoError(t, allow("active"))
+	require.ElementsMatch(t, []string{"active"}, limited())
+
+	// An evicted subject starts with a full bucket, as it would have had.
+	require.NoError(t, allow("runaway"))
+	require.NoError(t, allow("runaway"))
+	require.ErrorIs(t, allow("runaway"), ErrRateLimited)
+}
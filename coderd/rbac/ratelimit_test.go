@@ -0,0 +1,67 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	t.Parallel()
+
+	const burst = 5
+	// A limit of zero never refills, so only the burst is allowed.
+	auth := rbac.WithRateLimit(rbac.NewAuthorizer(prometheus.NewRegistry()),
+		slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), 0, burst)
+
+	subject := func() rbac.Subject {
+		return rbac.Subject{
+			ID:    uuid.NewString(),
+			Roles: rbac.RoleNames{rbac.RoleMember()},
+			Scope: rbac.ScopeAll,
+		}
+	}
+	runaway := subject()
+	other := subject()
+	ctx := context.Background()
+
+	for i := 0; i < burst; i++ {
+		err := auth.Authorize(ctx, runaway, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner(runaway.ID))
+		require.NoError(t, err)
+	}
+
+	err := auth.Authorize(ctx, runaway, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner(runaway.ID))
+	require.ErrorIs(t, err, rbac.ErrRateLimited)
+	_, err = auth.Prepare(ctx, runaway, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+	require.ErrorIs(t, err, rbac.ErrRateLimited)
+
+	// Other subjects are unaffected.
+	err = auth.Authorize(ctx, other, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner(other.ID))
+	require.NoError(t, err)
+	_, err = auth.Prepare(ctx, other, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+	require.NoError(t, err)
+}
+
+func TestWithRateLimitRefill(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.WithRateLimit(rbac.NewAuthorizer(prometheus.NewRegistry()),
+		slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), rate.Inf, 1)
+	subject := rbac.Subject{
+		ID:    uuid.NewString(),
+		Roles: rbac.RoleNames{rbac.RoleMember()},
+		Scope: rbac.ScopeAll,
+	}
+	for i := 0; i < 100; i++ {
+		err := auth.Authorize(context.Background(), subject, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner(subject.ID))
+		require.NoError(t, err)
+	}
+}
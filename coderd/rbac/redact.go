@@ -0,0 +1,82 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// Redaction is the set of fields of an object that must be removed before
+// it is returned to a subject.
+type Redaction map[string]bool
+
+// Fields returns the redacted fields, sorted.
+func (r Redaction) Fields() []string {
+	fields := make([]string, 0, len(r))
+	for field := range r {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Redacted returns true if the field must be removed.
+func (r Redaction) Redacted(field string) bool {
+	return r[field]
+}
+
+// AuthorizeWithRedaction authorizes the action, and returns the fields of
+// the object to redact for the subject. Redaction rules attach to roles with
+// Role.Redact. A field is redacted unless one of the roles that allows the
+// action on its own does not redact it, so an admin that can read audit logs
+// sees all fields, even if they also have a role that redacts them.
+//
+// If no single role allows the action, eg when it is allowed by an ACL, the
+// fields redacted by any of the subject's roles are redacted.
+func AuthorizeWithRedaction(ctx context.Context, auth Authorizer, subject Subject, action Action, object Object) (Redaction, error) {
+	err := auth.Authorize(ctx, subject, action, object)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := subject.expandRoles()
+	if err != nil {
+		return nil, xerrors.Errorf("expand roles: %w", err)
+	}
+
+	all := make(Redaction)
+	var redaction Redaction
+	for _, role := range roles {
+		for _, field := range role.Redact[object.Type] {
+			all[field] = true
+		}
+
+		// ACLs are not part of roles, so they are not considered.
+		err := auth.Authorize(ctx, Subject{
+			ID:    subject.ID,
+			Roles: Roles{role},
+			Scope: subject.Scope,
+		}, action, object.WithACLUserList(nil).WithGroupACL(nil))
+		if err != nil {
+			var unauthorized *UnauthorizedError
+			if !xerrors.As(err, &unauthorized) {
+				return nil, xerrors.Errorf("authorize role %q: %w", role.Name, err)
+			}
+			continue
+		}
+
+		fields := make(Redaction)
+		for _, field := range role.Redact[object.Type] {
+			// Only fields redacted by every allowing role are redacted.
+			if redaction == nil || redaction[field] {
+				fields[field] = true
+			}
+		}
+		redaction = fields
+	}
+	if redaction == nil {
+		return all, nil
+	}
+	return redaction, nil
+}
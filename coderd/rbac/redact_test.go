@@ -0,0 +1,75 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestAuthorizeWithRedaction(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	// A lower privileged role that can read audit logs, without the IP
+	// addresses and tokens.
+	logReader := rbac.Role{
+		Name: "log-reader",
+		Site: []rbac.Permission{
+			{ResourceType: rbac.ResourceAuditLog.Type, Action: rbac.ActionRead},
+		},
+		Redact: map[string][]string{
+			rbac.ResourceAuditLog.Type: {"ip", "token"},
+		},
+	}
+	owner, err := rbac.RoleByName(rbac.RoleOwner())
+	require.NoError(t, err)
+	member, err := rbac.RoleByName(rbac.RoleMember())
+	require.NoError(t, err)
+
+	authorize := func(t *testing.T, roles ...rbac.Role) (rbac.Redaction, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		t.Cleanup(cancel)
+		return rbac.AuthorizeWithRedaction(ctx, auth, rbac.Subject{
+			ID:    uuid.NewString(),
+			Roles: rbac.Roles(roles),
+			Scope: rbac.ScopeAll,
+		}, rbac.ActionRead, rbac.ResourceAuditLog)
+	}
+
+	t.Run("Redacted", func(t *testing.T) {
+		t.Parallel()
+		redaction, err := authorize(t, member, logReader)
+		require.NoError(t, err)
+		require.Equal(t, []string{"ip", "token"}, redaction.Fields())
+		require.True(t, redaction.Redacted("ip"))
+		require.False(t, redaction.Redacted("action"))
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		t.Parallel()
+		redaction, err := authorize(t, owner)
+		require.NoError(t, err)
+		require.Empty(t, redaction.Fields())
+	})
+
+	t.Run("AdminWithRedactedRole", func(t *testing.T) {
+		t.Parallel()
+		// The owner role allows reading all fields.
+		redaction, err := authorize(t, owner, logReader)
+		require.NoError(t, err)
+		require.Empty(t, redaction.Fields())
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		t.Parallel()
+		_, err := authorize(t, member)
+		var unauthorized *rbac.UnauthorizedError
+		require.ErrorAs(t, err, &unauthorized)
+	})
+}
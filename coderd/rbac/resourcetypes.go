@@ -0,0 +1,88 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// userOwnedResources are the resource types that can be owned by a user.
+// Other resources never have an owner, so the permissions roles grant over
+// the subject's own objects do not apply to them.
+var userOwnedResources = map[string]bool{
+	ResourceWorkspace.Type:                   true,
+	ResourceWorkspaceExecution.Type:          true,
+	ResourceWorkspaceApplicationConnect.Type: true,
+	ResourceAPIKey.Type:                      true,
+	ResourceUserData.Type:                    true,
+	ResourceFile.Type:                        true,
+}
+
+// AuthorizedResourceTypes returns the resource types the subject can perform
+// at least one action on, sorted by type. It is computed from the subject's
+// roles alone, by authorizing a site wide object and objects in each
+// organization the subject has a role in, owned by the subject if the
+// resource can have an owner.
+// Access granted only by the ACL of a specific object, eg a template shared
+// with the subject, is not included. Use this to decide what to show, eg in
+// a navigation menu, not to authorize requests.
+func AuthorizedResourceTypes(ctx context.Context, auth Authorizer, subject Subject) ([]string, error) {
+	roles, err := subject.expandRoles()
+	if err != nil {
+		return nil, xerrors.Errorf("expand roles: %w", err)
+	}
+	var orgIDs []uuid.UUID
+	for _, role := range roles {
+		for orgID := range role.Org {
+			id, err := uuid.Parse(orgID)
+			if err != nil {
+				continue
+			}
+			orgIDs = append(orgIDs, id)
+		}
+	}
+
+	var types []string
+	for _, resource := range AllResources() {
+		if resource.Type == ResourceWildcard.Type {
+			continue
+		}
+		if userOwnedResources[resource.Type] {
+			resource = resource.WithOwner(subject.ID)
+		}
+		objects := []Object{resource}
+		for _, orgID := range orgIDs {
+			objects = append(objects, resource.InOrg(orgID))
+		}
+
+		allowed, err := anyAllowed(ctx, auth, subject, objects)
+		if err != nil {
+			return nil, xerrors.Errorf("authorize %q: %w", resource.Type, err)
+		}
+		if allowed {
+			types = append(types, resource.Type)
+		}
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+// anyAllowed returns whether the subject can perform any action on any of
+// the objects.
+func anyAllowed(ctx context.Context, auth Authorizer, subject Subject, objects []Object) (bool, error) {
+	for _, action := range AllActions() {
+		for _, object := range objects {
+			err := auth.Authorize(ctx, subject, action, object)
+			if err == nil {
+				return true, nil
+			}
+			var unauthorized *UnauthorizedError
+			if !xerrors.As(err, &unauthorized) {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
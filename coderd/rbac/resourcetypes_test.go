@@ -0,0 +1,70 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestAuthorizedResourceTypes(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+
+	t.Run("Limited", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		types, err := rbac.AuthorizedResourceTypes(ctx, auth, rbac.Subject{
+			ID:    uuid.NewString(),
+			Roles: rbac.RoleNames{"auditor"},
+			Scope: rbac.ScopeAll,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			rbac.ResourceAuditLog.Type,
+			rbac.ResourceTemplate.Type,
+			rbac.ResourceTemplateVersion.Type,
+		}, types)
+	})
+
+	t.Run("Organization", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		orgID := uuid.New()
+		types, err := rbac.AuthorizedResourceTypes(ctx, auth, rbac.Subject{
+			ID:    uuid.NewString(),
+			Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgID)},
+			Scope: rbac.ScopeAll,
+		})
+		require.NoError(t, err)
+		// Members can read their organization, and own workspaces in it.
+		require.Contains(t, types, rbac.ResourceOrganization.Type)
+		require.Contains(t, types, rbac.ResourceWorkspace.Type)
+		require.NotContains(t, types, rbac.ResourceAuditLog.Type)
+	})
+
+	t.Run("Owner", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		types, err := rbac.AuthorizedResourceTypes(ctx, auth, rbac.Subject{
+			ID:    uuid.NewString(),
+			Roles: rbac.RoleNames{rbac.RoleOwner()},
+			Scope: rbac.ScopeAll,
+		})
+		require.NoError(t, err)
+		// Every resource type but the wildcard.
+		require.Len(t, types, len(rbac.AllResources())-1)
+	})
+}
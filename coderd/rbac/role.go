@@ -41,6 +41,10 @@ type Role struct {
 	// roles.
 	Org  map[string][]Permission `json:"org"`
 	User []Permission            `json:"user"`
+	// Redact lists the fields of each resource type that are hidden from
+	// subjects that are only allowed by this role, keyed by resource type.
+	// See AuthorizeWithRedaction. It is not passed to the policy.
+	Redact map[string][]string `json:"-"`
 }
 
 type Roles []Role
@@ -0,0 +1,36 @@
+package rbac_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+func TestParseScope(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+		scope, err := rbac.ParseScope("application_connect")
+		require.NoError(t, err)
+		require.Equal(t, rbac.ScopeApplicationConnect, scope)
+
+		expanded, err := scope.Expand()
+		require.NoError(t, err)
+		require.Equal(t, "Scope_application_connect", expanded.Name())
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		t.Parallel()
+		_, err := rbac.ParseScope("everything")
+		require.ErrorContains(t, err, `unknown scope "everything", expected one of all, application_connect`)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+		_, err := rbac.ParseScope("")
+		require.ErrorContains(t, err, "scope must not be empty")
+	})
+}
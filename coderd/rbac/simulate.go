@@ -0,0 +1,92 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/xerrors"
+)
+
+// SimulationQuery is a single action on an object to authorize during a
+// simulation.
+type SimulationQuery struct {
+	Action Action
+	Object Object
+}
+
+func (q SimulationQuery) String() string {
+	return fmt.Sprintf("%s:%s", q.Object.Type, q.Action)
+}
+
+// SimulationMatrix is the result of a simulation.
+// Allowed[i][j] is true if Subjects[i] is allowed to perform Queries[j].
+type SimulationMatrix struct {
+	Subjects []Subject
+	Queries  []SimulationQuery
+	Allowed  [][]bool
+}
+
+// String renders the matrix as a table with a row per subject and a column per
+// query. This makes the matrix easy to compare in policy regression tests.
+func (m SimulationMatrix) String() string {
+	var s strings.Builder
+	w := tabwriter.NewWriter(&s, 0, 0, 2, ' ', 0)
+
+	header := make([]string, 0, len(m.Queries)+1)
+	header = append(header, "subject")
+	for _, q := range m.Queries {
+		header = append(header, q.String())
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for i, subject := range m.Subjects {
+		row := make([]string, 0, len(m.Queries)+1)
+		row = append(row, subject.ID)
+		for _, allowed := range m.Allowed[i] {
+			if allowed {
+				row = append(row, "allow")
+			} else {
+				row = append(row, "deny")
+			}
+		}
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	_ = w.Flush()
+	return s.String()
+}
+
+// Simulate authorizes every query for every subject and returns the results
+// as a matrix. It is intended for policy testing, to assert the RBAC behavior
+// does not change unexpectedly.
+//
+// A denied query is not an error. Any other authorization error, eg the
+// context being canceled, is returned, as the query was not decided.
+func Simulate(ctx context.Context, auth Authorizer, subjects []Subject, queries []SimulationQuery) (SimulationMatrix, error) {
+	matrix := SimulationMatrix{
+		Subjects: subjects,
+		Queries:  queries,
+		Allowed:  make([][]bool, len(subjects)),
+	}
+
+	for i, subject := range subjects {
+		matrix.Allowed[i] = make([]bool, len(queries))
+		for j, query := range queries {
+			if err := ctx.Err(); err != nil {
+				return SimulationMatrix{}, xerrors.Errorf("simulate: %w", err)
+			}
+			err := auth.Authorize(ctx, subject, query.Action, query.Object)
+			if err == nil {
+				matrix.Allowed[i][j] = true
+				continue
+			}
+			var unauthorized *UnauthorizedError
+			if !xerrors.As(err, &unauthorized) {
+				return SimulationMatrix{}, xerrors.Errorf("simulate %s for subject %q: %w", query, subject.ID, err)
+			}
+		}
+	}
+
+	return matrix, nil
+}
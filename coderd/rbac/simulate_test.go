@@ -0,0 +1,95 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSimulate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	orgID := uuid.New()
+	memberID := uuid.NewString()
+	subjects := []rbac.Subject{
+		{
+			ID:    "owner",
+			Roles: rbac.RoleNames{rbac.RoleOwner(), rbac.RoleMember()},
+			Scope: rbac.ScopeAll,
+		},
+		{
+			ID:    memberID,
+			Roles: rbac.RoleNames{rbac.RoleMember(), rbac.RoleOrgMember(orgID)},
+			Scope: rbac.ScopeAll,
+		},
+		{
+			ID:    "auditor",
+			Roles: rbac.RoleNames{"auditor", rbac.RoleMember()},
+			Scope: rbac.ScopeAll,
+		},
+		{
+			ID:    "app-connect",
+			Roles: rbac.RoleNames{rbac.RoleOwner()},
+			Scope: rbac.ScopeApplicationConnect,
+		},
+	}
+	queries := []rbac.SimulationQuery{
+		{Action: rbac.ActionRead, Object: rbac.ResourceWorkspace.InOrg(orgID).WithOwner(memberID)},
+		{Action: rbac.ActionDelete, Object: rbac.ResourceWorkspace.InOrg(orgID).WithOwner("other")},
+		{Action: rbac.ActionRead, Object: rbac.ResourceAuditLog},
+		{Action: rbac.ActionCreate, Object: rbac.ResourceTemplate.InOrg(orgID)},
+		{Action: rbac.ActionCreate, Object: rbac.ResourceWorkspaceApplicationConnect.InOrg(orgID).WithOwner(memberID)},
+	}
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	matrix, err := rbac.Simulate(ctx, auth, subjects, queries)
+	require.NoError(t, err)
+	require.Len(t, matrix.Allowed, len(subjects))
+
+	for i, subject := range subjects {
+		require.Len(t, matrix.Allowed[i], len(queries))
+		for j, query := range queries {
+			err := auth.Authorize(ctx, subject, query.Action, query.Object)
+			require.Equalf(t, err == nil, matrix.Allowed[i][j], "subject %q query %s", subject.ID, query)
+		}
+	}
+
+	// Spot check some cells.
+	require.True(t, matrix.Allowed[0][1], "owner can delete any workspace")
+	require.True(t, matrix.Allowed[1][0], "member can read own workspace")
+	require.False(t, matrix.Allowed[1][1], "member cannot delete other workspaces")
+	require.True(t, matrix.Allowed[2][2], "auditor can read audit logs")
+	require.False(t, matrix.Allowed[3][0], "scope denies workspace read")
+	require.True(t, matrix.Allowed[3][4], "scope allows application connect")
+
+	require.Contains(t, matrix.String(), "audit_log:read")
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := rbac.Simulate(ctx, auth, subjects, queries)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		// Errors other than denials are returned, not recorded as a deny.
+		limited := rbac.WithRateLimit(auth, slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), 0, 1)
+		_, err := rbac.Simulate(ctx, limited, subjects, queries)
+		require.ErrorIs(t, err, rbac.ErrRateLimited)
+	})
+}
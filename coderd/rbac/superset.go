@@ -0,0 +1,131 @@
+package rbac
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// IsSuperset returns true if role a grants everything role b grants, and
+// does not deny anything b does not deny. Identical roles are supersets of
+// each other. Both roles are evaluated by the authorizer, so the comparison
+// follows the policy.
+//
+// The roles are compared for every resource type and action, on objects
+// outside of an organization, in each organization either role is scoped
+// to, and in an organization neither is a member of, both owned and not
+// owned by the subject. A negated permission in a that b does not have
+// breaks the superset even if b does not grant the action, as subjects with
+// other roles would lose that permission with a. To find those, the roles
+// are also compared along with a role that grants the action at each level.
+func IsSuperset(ctx context.Context, auth Authorizer, a, b Role) (bool, error) {
+	resourceTypes := make(map[string]struct{})
+	for _, resource := range AllResources() {
+		resourceTypes[resource.Type] = struct{}{}
+	}
+	actions := make(map[Action]struct{})
+	for _, action := range AllActions() {
+		actions[action] = struct{}{}
+	}
+	orgIDs := map[string]struct{}{
+		// An organization neither role is a member of.
+		uuid.NewString(): {},
+	}
+	// Include resources and actions that only exist in the roles.
+	for _, role := range []Role{a, b} {
+		for _, perms := range rolePermissions(role) {
+			for _, perm := range perms {
+				resourceTypes[perm.ResourceType] = struct{}{}
+				actions[perm.Action] = struct{}{}
+			}
+		}
+		for orgID := range role.Org {
+			orgIDs[orgID] = struct{}{}
+		}
+	}
+
+	subjectID := uuid.NewString()
+	for resourceType := range resourceTypes {
+		for action := range actions {
+			objects := []Object{{Type: resourceType}}
+			for orgID := range orgIDs {
+				objects = append(objects, Object{Type: resourceType, OrgID: orgID})
+			}
+			for _, object := range objects {
+				for _, owned := range []bool{false, true} {
+					object := object
+					if owned {
+						object = object.WithOwner(subjectID)
+					}
+					for _, with := range probeRoles(resourceType, action, object.OrgID) {
+						allowedB, err := rolesAllow(ctx, auth, subjectID, Roles{b}, with, action, object)
+						if err != nil {
+							return false, err
+						}
+						if !allowedB {
+							continue
+						}
+						allowedA, err := rolesAllow(ctx, auth, subjectID, Roles{a}, with, action, object)
+						if err != nil {
+							return false, err
+						}
+						if !allowedA {
+							return false, nil
+						}
+					}
+				}
+			}
+		}
+	}
+	return true, nil
+}
+
+// probeRoles returns the extra roles to compare the roles with: none, and
+// a role granting the action at the site, organization and user level.
+func probeRoles(resourceType string, action Action, orgID string) []*Role {
+	grant := []Permission{{ResourceType: resourceType, Action: action}}
+	probes := []*Role{
+		nil,
+		{Name: "superset-site", Site: grant},
+		{Name: "superset-user", User: grant},
+	}
+	if orgID != "" {
+		probes = append(probes, &Role{Name: "superset-org", Org: map[string][]Permission{orgID: grant}})
+	}
+	return probes
+}
+
+// rolesAllow returns whether a subject with the roles, and the extra role
+// if it is not nil, can perform the action on the object.
+func rolesAllow(ctx context.Context, auth Authorizer, subjectID string, roles Roles, with *Role, action Action, object Object) (bool, error) {
+	if with != nil {
+		roles = append(append(Roles{}, roles...), *with)
+	}
+	err := auth.Authorize(ctx, Subject{
+		ID:    subjectID,
+		Roles: roles,
+		Scope: ScopeAll,
+	}, action, object)
+	if err == nil {
+		return true, nil
+	}
+	var unauthorized *UnauthorizedError
+	if !xerrors.As(err, &unauthorized) {
+		return false, xerrors.Errorf("authorize %s %s: %w", action, object.Type, err)
+	}
+	return false, nil
+}
+
+// rolePermissions returns the permissions of the role by level, keyed
+// "site", "user" and "org:<id>".
+func rolePermissions(role Role) map[string][]Permission {
+	perms := map[string][]Permission{
+		"site": role.Site,
+		"user": role.User,
+	}
+	for orgID, orgPerms := range role.Org {
+		perms["org:"+orgID] = orgPerms
+	}
+	return perms
+}
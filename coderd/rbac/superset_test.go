@@ -0,0 +1,77 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+func TestIsSuperset(t *testing.T) {
+	t.Parallel()
+
+	auth := rbac.NewAuthorizer(prometheus.NewRegistry())
+	isSuperset := func(t *testing.T, a, b rbac.Role) bool {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		superset, err := rbac.IsSuperset(ctx, auth, a, b)
+		require.NoError(t, err)
+		return superset
+	}
+	roleByName := func(t *testing.T, name string) rbac.Role {
+		t.Helper()
+		role, err := rbac.RoleByName(name)
+		require.NoError(t, err)
+		return role
+	}
+
+	t.Run("Identical", func(t *testing.T) {
+		t.Parallel()
+		member := roleByName(t, rbac.RoleMember())
+		require.True(t, isSuperset(t, member, member))
+		require.True(t, isSuperset(t, member, roleByName(t, rbac.RoleMember())))
+	})
+
+	t.Run("OwnerMember", func(t *testing.T) {
+		t.Parallel()
+		owner := roleByName(t, rbac.RoleOwner())
+		member := roleByName(t, rbac.RoleMember())
+		require.True(t, isSuperset(t, owner, member))
+		require.False(t, isSuperset(t, member, owner))
+	})
+
+	t.Run("Organization", func(t *testing.T) {
+		t.Parallel()
+		orgID := uuid.New()
+		orgAdmin := roleByName(t, rbac.RoleOrgAdmin(orgID))
+		orgMember := roleByName(t, rbac.RoleOrgMember(orgID))
+		require.True(t, isSuperset(t, orgAdmin, orgMember))
+		require.False(t, isSuperset(t, orgMember, orgAdmin))
+		// Permissions in a different organization are not included.
+		require.False(t, isSuperset(t, orgAdmin, roleByName(t, rbac.RoleOrgMember(uuid.New()))))
+	})
+
+	t.Run("ExtraDeny", func(t *testing.T) {
+		t.Parallel()
+		member := roleByName(t, rbac.RoleMember())
+		denied := roleByName(t, rbac.RoleMember())
+		denied.Site = append(append([]rbac.Permission{}, denied.Site...), rbac.Permission{
+			Negate:       true,
+			ResourceType: rbac.ResourceTemplate.Type,
+			Action:       rbac.ActionCreate,
+		})
+		// The member cannot create templates either, but the deny would
+		// remove the permission from subjects with other roles.
+		require.False(t, isSuperset(t, denied, member))
+		require.True(t, isSuperset(t, member, denied))
+
+		owner := roleByName(t, rbac.RoleOwner())
+		require.True(t, isSuperset(t, owner, denied))
+	})
+}
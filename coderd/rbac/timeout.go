@@ -0,0 +1,96 @@
+package rbac
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac/regosql"
+)
+
+// WithTimeout bounds every call to the authorizer by the timeout. Calls that
+// do not complete in time return an error wrapping context.DeadlineExceeded.
+// The authorizer must respect context cancellation, as the rego authorizer
+// does. A timeout <= 0 returns the authorizer unchanged.
+func WithTimeout(auth Authorizer, timeout time.Duration) Authorizer {
+	if timeout <= 0 {
+		return auth
+	}
+	return &timeoutAuthorizer{
+		auth:    auth,
+		timeout: timeout,
+	}
+}
+
+type timeoutAuthorizer struct {
+	auth    Authorizer
+	timeout time.Duration
+}
+
+var (
+	_ Authorizer         = (*timeoutAuthorizer)(nil)
+	_ SubjectInvalidator = (*timeoutAuthorizer)(nil)
+)
+
+func (t *timeoutAuthorizer) Authorize(ctx context.Context, subject Subject, action Action, object Object) error {
+	_, err := withTimeout(ctx, t.timeout, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, t.auth.Authorize(ctx, subject, action, object)
+	})
+	if err != nil {
+		return xerrors.Errorf("authorize: %w", err)
+	}
+	return nil
+}
+
+func (t *timeoutAuthorizer) Prepare(ctx context.Context, subject Subject, action Action, objectType string) (PreparedAuthorized, error) {
+	prepared, err := withTimeout(ctx, t.timeout, func(ctx context.Context) (PreparedAuthorized, error) {
+		return t.auth.Prepare(ctx, subject, action, objectType)
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("prepare: %w", err)
+	}
+	return &timeoutPrepared{
+		prepared: prepared,
+		timeout:  t.timeout,
+	}, nil
+}
+
+type timeoutPrepared struct {
+	prepared PreparedAuthorized
+	timeout  time.Duration
+}
+
+func (t *timeoutPrepared) Authorize(ctx context.Context, object Object) error {
+	_, err := withTimeout(ctx, t.timeout, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, t.prepared.Authorize(ctx, object)
+	})
+	if err != nil {
+		return xerrors.Errorf("authorize prepared: %w", err)
+	}
+	return nil
+}
+
+func (t *timeoutPrepared) CompileToSQL(ctx context.Context, cfg regosql.ConvertConfig) (string, error) {
+	return t.prepared.CompileToSQL(ctx, cfg)
+}
+
+// withTimeout calls fn with a context that is canceled at the timeout. If the
+// context is done when fn returns an error, the context's error is returned
+// instead, as the authorizer fails with an UnauthorizedError that hides the
+// cause when evaluation is canceled.
+func withTimeout[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	value, err := fn(ctx)
+	if err != nil && ctx.Err() != nil {
+		var empty T
+		return empty, ctx.Err()
+	}
+	return value, err
+}
+
+func (t *timeoutAuthorizer) InvalidateSubject(subjectID string) {
+	InvalidateSubject(t.auth, subjectID)
+}
@@ -0,0 +1,86 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/testutil"
+)
+
+// blockingAuthorizer blocks until the context is done. It then fails with
+// an UnauthorizedError, like the rego authorizer does when evaluation is
+// canceled.
+type blockingAuthorizer struct {
+	rbac.Authorizer
+}
+
+func (blockingAuthorizer) Authorize(ctx context.Context, _ rbac.Subject, _ rbac.Action, _ rbac.Object) error {
+	<-ctx.Done()
+	return rbac.ForbiddenWithInternal(xerrors.Errorf("eval rego: %w", ctx.Err()), nil, nil)
+}
+
+func (blockingAuthorizer) Prepare(ctx context.Context, _ rbac.Subject, _ rbac.Action, _ string) (rbac.PreparedAuthorized, error) {
+	<-ctx.Done()
+	return nil, xerrors.Errorf("prepare: %w", ctx.Err())
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	subject := rbac.Subject{
+		ID:    uuid.NewString(),
+		Roles: rbac.RoleNames{rbac.RoleMember()},
+		Scope: rbac.ScopeAll,
+	}
+	object := rbac.ResourceWorkspace.WithOwner(subject.ID)
+
+	t.Run("Blocked", func(t *testing.T) {
+		t.Parallel()
+
+		const timeout = 50 * time.Millisecond
+		auth := rbac.WithTimeout(blockingAuthorizer{}, timeout)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		start := time.Now()
+		err := auth.Authorize(ctx, subject, rbac.ActionRead, object)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		_, err = auth.Prepare(ctx, subject, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, time.Since(start), testutil.WaitShort)
+		require.NoError(t, ctx.Err(), "the parent context is not affected")
+	})
+
+	t.Run("Passthrough", func(t *testing.T) {
+		t.Parallel()
+
+		auth := rbac.WithTimeout(rbac.NewAuthorizer(prometheus.NewRegistry()), testutil.WaitShort)
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		err := auth.Authorize(ctx, subject, rbac.ActionRead, object)
+		require.NoError(t, err)
+		err = auth.Authorize(ctx, subject, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner(uuid.NewString()))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, context.DeadlineExceeded)
+
+		prepared, err := auth.Prepare(ctx, subject, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+		require.NoError(t, err)
+		require.NoError(t, prepared.Authorize(ctx, object))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		inner := rbac.NewAuthorizer(prometheus.NewRegistry())
+		require.Same(t, inner, rbac.WithTimeout(inner, 0))
+	})
+}
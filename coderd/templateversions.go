@@ -572,9 +572,8 @@ func (api *API) fetchTemplateVersionDryRunJob(rw http.ResponseWriter, r *http.Re
 	}
 
 	// Do a workspace resource check since it's basically a workspace dry-run.
-	if !api.Authorize(r, rbac.ActionRead,
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionRead,
 		rbac.ResourceWorkspace.InOrg(templateVersion.OrganizationID).WithOwner(job.InitiatorID.String())) {
-		httpapi.Forbidden(rw)
 		return database.ProvisionerJob{}, false
 	}
 
@@ -913,7 +912,10 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 	defer commitAudit()
 	aReq.Old = template
 
-	if !api.Authorize(r, rbac.ActionUpdate, template) {
+	// Promoting a version only requires managing the template's versions,
+	// not full control of the template.
+	if !api.Authorize(r, rbac.ActionUpdate, template) &&
+		!api.Authorize(r, rbac.ActionUpdate, rbac.ResourceTemplateVersion.InOrg(template.OrganizationID)) {
 		httpapi.ResourceNotFound(rw)
 		return
 	}
@@ -1049,13 +1051,11 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 	var err error
 	// if example id is specified we need to copy the embedded tar into a new file in the database
 	if req.ExampleID != "" {
-		if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
-			httpapi.Forbidden(rw)
+		if !api.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
 			return
 		}
 		// ensure we can read the file that either already exists or will be created
-		if !api.Authorize(r, rbac.ActionRead, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
-			httpapi.Forbidden(rw)
+		if !api.AuthorizeOrForbid(rw, r, rbac.ActionRead, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
 			return
 		}
 
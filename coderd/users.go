@@ -265,8 +265,7 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 	defer commitAudit()
 
 	// Create the user on the site.
-	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceUser) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceUser) {
 		return
 	}
 
@@ -360,8 +359,7 @@ func (api *API) deleteUser(rw http.ResponseWriter, r *http.Request) {
 	aReq.Old = user
 	defer commitAudit()
 
-	if !api.Authorize(r, rbac.ActionDelete, rbac.ResourceUser) {
-		httpapi.Forbidden(rw)
+	if !api.AuthorizeOrForbid(rw, r, rbac.ActionDelete, rbac.ResourceUser) {
 		return
 	}
 
@@ -665,8 +663,7 @@ func (api *API) putUserPassword(rw http.ResponseWriter, r *http.Request) {
 
 	// admins can change passwords without sending old_password
 	if params.OldPassword == "" {
-		if !api.Authorize(r, rbac.ActionUpdate, user) {
-			httpapi.Forbidden(rw)
+		if !api.AuthorizeOrForbid(rw, r, rbac.ActionUpdate, user) {
 			return
 		}
 	} else {
@@ -842,14 +839,12 @@ func (api *API) putUserRoles(rw http.ResponseWriter, r *http.Request) {
 	added, removed := rbac.ChangeRoleSet(user.RBACRoles, impliedTypes)
 
 	// Assigning a role requires the create permission.
-	if len(added) > 0 && !api.Authorize(r, rbac.ActionCreate, rbac.ResourceRoleAssignment) {
-		httpapi.Forbidden(rw)
+	if len(added) > 0 && !api.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceRoleAssignment) {
 		return
 	}
 
 	// Removing a role requires the delete permission.
-	if len(removed) > 0 && !api.Authorize(r, rbac.ActionDelete, rbac.ResourceRoleAssignment) {
-		httpapi.Forbidden(rw)
+	if len(removed) > 0 && !api.AuthorizeOrForbid(rw, r, rbac.ActionDelete, rbac.ResourceRoleAssignment) {
 		return
 	}
 
@@ -903,6 +898,8 @@ func (api *API) updateSiteUserRoles(ctx context.Context, args database.UpdateUse
 	if err != nil {
 		return database.User{}, xerrors.Errorf("update site roles: %w", err)
 	}
+	// Free the decisions cached with the old roles.
+	rbac.InvalidateSubject(api.Authorizer, updatedUser.ID.String())
 	return updatedUser, nil
 }
 
@@ -38,6 +38,30 @@ func New(serverURL *url.URL) *Client {
 // scoped to a workspace agent.
 type Client struct {
 	SDK *codersdk.Client
+	// Capabilities are sent to coderd with the metadata request. See
+	// HasCapability for the ones coderd acknowledged.
+	Capabilities []Capability
+	// StatsFullReportEvery makes ReportStats send only the stats that
+	// changed since the last full report, with a full report every
+	// StatsFullReportEvery reports to resync the server. Zero sends full
+	// reports only. A full report is also sent after a failed report, and
+	// deltas stop if coderd does not support them.
+	StatsFullReportEvery int
+	// ProcessStats enables per-process metrics in the stats sent by
+	// ReportStats. Collecting them is expensive, so they are only sent when
+	// this is set.
+	ProcessStats func() []ProcessStats
+	// EnableDebugState enables the /debug/state endpoint of DebugHandler.
+	EnableDebugState bool
+	// CorrectTimestamps shifts the timestamps of reported errors and
+	// startup logs by ClockOffset, so they match coderd's clock.
+	CorrectTimestamps bool
+
+	health       healthTracker
+	metrics      *clientMetrics
+	capabilities capabilitySet
+	debug        debugState
+	clock        clockTracker
 }
 
 func (c *Client) SetSessionToken(token string) {
@@ -78,11 +102,15 @@ type Metadata struct {
 	StartupScriptTimeout time.Duration           `json:"startup_script_timeout"`
 	Directory            string                  `json:"directory"`
 	MOTDFile             string                  `json:"motd_file"`
+	// Capabilities are the capabilities of the agent that coderd supports.
+	Capabilities []Capability `json:"capabilities,omitempty"`
 }
 
 // Metadata fetches metadata for the currently authenticated workspace agent.
 func (c *Client) Metadata(ctx context.Context) (Metadata, error) {
-	res, err := c.SDK.Request(ctx, http.MethodGet, "/api/v2/workspaceagents/me/metadata", nil)
+	res, err := c.SDK.Request(ctx, http.MethodGet, "/api/v2/workspaceagents/me/metadata", nil, withCapabilities(c.Capabilities))
+	c.health.observe(res, err)
+	c.clock.observe(res)
 	if err != nil {
 		return Metadata{}, err
 	}
@@ -95,10 +123,14 @@ func (c *Client) Metadata(ctx context.Context) (Metadata, error) {
 	if err != nil {
 		return Metadata{}, err
 	}
-	accessingPort := c.SDK.URL.Port()
+	c.capabilities.acknowledge(c.Capabilities, agentMeta.Capabilities)
+	// The URL that served the request, which differs from SDK.URL after
+	// failing over.
+	serverURL := c.serverURL()
+	accessingPort := serverURL.Port()
 	if accessingPort == "" {
 		accessingPort = "80"
-		if c.SDK.URL.Scheme == "https" {
+		if serverURL.Scheme == "https" {
 			accessingPort = "443"
 		}
 	}
@@ -112,6 +144,10 @@ func (c *Client) Metadata(ctx context.Context) (Metadata, error) {
 	//
 	// This converts all built-in DERPs to use the access URL that the
 	// metadata request was performed with.
+	if agentMeta.DERPMap == nil {
+		c.debug.recordMetadata(agentMeta)
+		return agentMeta, nil
+	}
 	for _, region := range agentMeta.DERPMap.Regions {
 		if !region.EmbeddedRelay {
 			continue
@@ -121,18 +157,32 @@ func (c *Client) Metadata(ctx context.Context) (Metadata, error) {
 			if node.STUNOnly {
 				continue
 			}
-			node.HostName = c.SDK.URL.Hostname()
+			node.HostName = serverURL.Hostname()
 			node.DERPPort = accessPort
-			node.ForceHTTP = c.SDK.URL.Scheme == "http"
+			node.ForceHTTP = serverURL.Scheme == "http"
 		}
 	}
+	c.debug.recordMetadata(agentMeta)
 	return agentMeta, nil
 }
 
+// EnvironmentVariables fetches the environment variables configured for the
+// workspace agent. The map is empty, not nil, if there are none.
+func (c *Client) EnvironmentVariables(ctx context.Context) (map[string]string, error) {
+	agentMeta, err := c.Metadata(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get metadata: %w", err)
+	}
+	if agentMeta.EnvironmentVariables == nil {
+		return map[string]string{}, nil
+	}
+	return agentMeta.EnvironmentVariables, nil
+}
+
 // Listen connects to the workspace agent coordinate WebSocket
 // that handles connection negotiation.
 func (c *Client) Listen(ctx context.Context) (net.Conn, error) {
-	coordinateURL, err := c.SDK.URL.Parse("/api/v2/workspaceagents/me/coordinate")
+	coordinateURL, err := c.serverURL().Parse("/api/v2/workspaceagents/me/coordinate")
 	if err != nil {
 		return nil, xerrors.Errorf("parse url: %w", err)
 	}
@@ -380,6 +430,16 @@ func (c *Client) ReportStats(
 		timer := time.NewTimer(time.Nanosecond)
 		defer timer.Stop()
 
+		var (
+			// lastFull is the last full report, which deltas are relative to.
+			lastFull *Stats
+			// reports is the number of reports since lastFull, including it.
+			reports int
+			// noDeltas is set once coderd responds that it does not
+			// support deltas.
+			noDeltas bool
+		)
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -389,14 +449,43 @@ func (c *Client) ReportStats(
 
 			var nextInterval time.Duration
 			for r := retry.New(100*time.Millisecond, time.Minute); r.Wait(ctx); {
-				resp, err := c.PostStats(ctx, getStats())
+				stats := getStats()
+				if c.ProcessStats != nil {
+					stats.Processes = c.ProcessStats()
+				}
+				full := lastFull == nil || noDeltas || c.StatsFullReportEvery <= 0 || reports%c.StatsFullReportEvery == 0
+				var (
+					resp StatsResponse
+					err  error
+				)
+				if full {
+					resp, err = c.PostStats(ctx, stats)
+				} else {
+					resp, err = c.PostStatsDelta(ctx, StatsDeltaFrom(lastFull, stats))
+					var sdkErr *codersdk.Error
+					if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+						// Older versions of coderd only accept full reports.
+						noDeltas = true
+						full = true
+						resp, err = c.PostStats(ctx, stats)
+					}
+				}
 				if err != nil {
+					// coderd may or may not have the failed report, so the
+					// next report must be a full one.
+					lastFull = nil
 					if !xerrors.Is(err, context.Canceled) {
 						log.Error(ctx, "report stats", slog.Error(err))
 					}
 					continue
 				}
 
+				c.debug.recordStats(stats)
+				if full {
+					lastFull = stats
+					reports = 0
+				}
+				reports++
 				nextInterval = resp.ReportInterval
 				break
 			}
@@ -425,6 +514,30 @@ type Stats struct {
 	TxPackets int64 `json:"tx_packets"`
 	// TxBytes is the number of transmitted bytes.
 	TxBytes int64 `json:"tx_bytes"`
+
+	// The number of clients connected to the agent by type. Servers that
+	// do not know about these fields ignore them.
+
+	// SessionCountSSH is the number of connected SSH sessions.
+	SessionCountSSH int64 `json:"session_count_ssh"`
+	// SessionCountReconnectingPTY is the number of connected web terminals.
+	SessionCountReconnectingPTY int64 `json:"session_count_reconnecting_pty"`
+	// SessionCountPortForward is the number of open port forwards.
+	SessionCountPortForward int64 `json:"session_count_port_forward"`
+
+	// Processes are per-process metrics, only sent if the agent enables
+	// them. Servers that do not know about this field ignore it.
+	Processes []ProcessStats `json:"processes,omitempty"`
+}
+
+// ProcessStats are the metrics of a single process in the workspace.
+type ProcessStats struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+	// CPUSeconds is the total CPU time used by the process.
+	CPUSeconds float64 `json:"cpu_seconds"`
+	// RSSBytes is the resident set size of the process.
+	RSSBytes int64 `json:"rss_bytes"`
 }
 
 type StatsResponse struct {
@@ -434,14 +547,20 @@ type StatsResponse struct {
 }
 
 func (c *Client) PostStats(ctx context.Context, stats *Stats) (StatsResponse, error) {
-	res, err := c.SDK.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/report-stats", stats)
+	res, err := c.compressedRequest(ctx, http.MethodPost, "/api/v2/workspaceagents/me/report-stats", stats)
+	c.health.observe(res, err)
+	c.clock.observe(res)
 	if err != nil {
+		c.metrics.observeStatsReport(err)
 		return StatsResponse{}, xerrors.Errorf("send request: %w", err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
-		return StatsResponse{}, codersdk.ReadBodyAsError(res)
+		err = codersdk.ReadBodyAsError(res)
+		c.metrics.observeStatsReport(err)
+		return StatsResponse{}, err
 	}
+	c.metrics.observeStatsReport(nil)
 
 	var interval StatsResponse
 	err = json.NewDecoder(res.Body).Decode(&interval)
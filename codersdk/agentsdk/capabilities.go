@@ -0,0 +1,89 @@
+package agentsdk
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// Capability is a feature the agent supports. The agent sends its
+// capabilities to coderd, which acknowledges the ones it supports too.
+type Capability string
+
+const (
+	CapabilityGzip           Capability = "gzip"
+	CapabilityZstd           Capability = "zstd"
+	CapabilityH2C            Capability = "h2c"
+	CapabilityWebsocketStats Capability = "websocket_stats"
+)
+
+// CapabilitiesHeader lists the capabilities of the agent, separated by
+// commas. It is sent with the metadata request.
+const CapabilitiesHeader = "Coder-Agent-Capabilities"
+
+// ParseCapabilities parses the value of CapabilitiesHeader.
+func ParseCapabilities(header string) []Capability {
+	var capabilities []Capability
+	for _, capability := range strings.Split(header, ",") {
+		capability = strings.TrimSpace(capability)
+		if capability == "" {
+			continue
+		}
+		capabilities = append(capabilities, Capability(capability))
+	}
+	return capabilities
+}
+
+// capabilitySet holds the capabilities acknowledged by coderd.
+type capabilitySet struct {
+	mu           sync.Mutex
+	acknowledged map[Capability]bool
+}
+
+// withCapabilities adds the capabilities to the request.
+func withCapabilities(capabilities []Capability) codersdk.RequestOption {
+	return func(r *http.Request) {
+		if len(capabilities) == 0 {
+			return
+		}
+		values := make([]string, 0, len(capabilities))
+		for _, capability := range capabilities {
+			values = append(values, string(capability))
+		}
+		r.Header.Set(CapabilitiesHeader, strings.Join(values, ","))
+	}
+}
+
+// acknowledge stores the capabilities acknowledged by coderd. Capabilities
+// the client did not send are ignored.
+func (s *capabilitySet) acknowledge(supported, acknowledged []Capability) {
+	sent := make(map[Capability]bool, len(supported))
+	for _, capability := range supported {
+		sent[capability] = true
+	}
+	set := make(map[Capability]bool, len(acknowledged))
+	for _, capability := range acknowledged {
+		if sent[capability] {
+			set[capability] = true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acknowledged = set
+}
+
+func (s *capabilitySet) has(capability Capability) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acknowledged[capability]
+}
+
+// HasCapability returns whether coderd acknowledged the capability in the
+// last metadata response. Use it before making requests that rely on the
+// capability.
+func (c *Client) HasCapability(capability Capability) bool {
+	return c.capabilities.has(capability)
+}
@@ -0,0 +1,63 @@
+package agentsdk
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// clockTracker estimates how far coderd's clock is ahead of the local
+// clock from the Date header of responses. The zero value is ready to use.
+type clockTracker struct {
+	mu     sync.Mutex
+	offset time.Duration
+	synced bool
+}
+
+// observe updates the offset from the Date header of a response. Responses
+// without a valid header are ignored.
+func (c *clockTracker) observe(res *http.Response) {
+	if res == nil {
+		return
+	}
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	// The header is truncated to the second, so on average coderd's clock
+	// was half a second later than the header.
+	offset := date.Add(500 * time.Millisecond).Sub(now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset = offset
+	c.synced = true
+}
+
+func (c *clockTracker) get() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offset, c.synced
+}
+
+// ClockOffset returns how far coderd's clock is ahead of the local clock,
+// negative if it is behind, as of the last response from coderd. It is
+// false until a response with a Date header is received. The Date header
+// has a resolution of a second, so the offset corrects clocks that drift,
+// not small differences.
+func (c *Client) ClockOffset() (time.Duration, bool) {
+	return c.clock.get()
+}
+
+// Now returns the current time by coderd's clock, or the local time if the
+// offset is not known yet.
+func (c *Client) Now() time.Time {
+	return c.correctTime(time.Now())
+}
+
+// correctTime shifts a local timestamp to coderd's clock.
+func (c *Client) correctTime(t time.Time) time.Time {
+	offset, _ := c.clock.get()
+	return t.Add(offset)
+}
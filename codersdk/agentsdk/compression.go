@@ -0,0 +1,126 @@
+package agentsdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/xerrors"
+)
+
+// Encodings of request bodies, negotiated with coderd through the
+// capabilities exchange.
+const (
+	EncodingZstd     = "zstd"
+	EncodingGzip     = "gzip"
+	EncodingIdentity = "identity"
+)
+
+// RequestEncoding returns the encoding of compressed request bodies. zstd is
+// preferred when coderd acknowledged both the zstd and gzip capabilities,
+// and bodies are not compressed when it acknowledged neither.
+func (c *Client) RequestEncoding() string {
+	switch {
+	case c.HasCapability(CapabilityZstd):
+		return EncodingZstd
+	case c.HasCapability(CapabilityGzip):
+		return EncodingGzip
+	default:
+		return EncodingIdentity
+	}
+}
+
+// compressedRequest sends body as JSON, compressed with the negotiated
+// encoding. It is used for requests with large bodies, such as stats and
+// logs.
+func (c *Client) compressedRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	encoding := c.RequestEncoding()
+	if encoding == EncodingIdentity {
+		return c.SDK.Request(ctx, method, path, body)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, xerrors.Errorf("encode body: %w", err)
+	}
+	var compressed []byte
+	switch encoding {
+	case EncodingZstd:
+		encoder, err := getZstdEncoder()
+		if err != nil {
+			return nil, xerrors.Errorf("create zstd encoder: %w", err)
+		}
+		compressed = encoder.EncodeAll(data, nil)
+	default:
+		compressed, err = gzipCompress(data)
+		if err != nil {
+			return nil, xerrors.Errorf("compress body: %w", err)
+		}
+	}
+
+	return c.SDK.Request(ctx, method, path, compressed, func(r *http.Request) {
+		r.Header.Set("Content-Encoding", encoding)
+	})
+}
+
+var (
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+	zstdEncoderErr  error
+)
+
+// getZstdEncoder returns the encoder shared by all clients, which is created
+// on first use. EncodeAll is safe to call concurrently.
+func getZstdEncoder() (*zstd.Encoder, error) {
+	zstdEncoderOnce.Do(func() {
+		zstdEncoder, zstdEncoderErr = zstd.NewWriter(nil)
+	})
+	return zstdEncoder, zstdEncoderErr
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// gzipCompress compresses data with a gzip writer from the pool.
+func gzipCompress(data []byte) ([]byte, error) {
+	w, _ := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(w)
+	var buf bytes.Buffer
+	w.Reset(&buf)
+	_, err := w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecompressRequest returns the body of a request compressed by the agent,
+// based on its Content-Encoding.
+func DecompressRequest(r *http.Request) (io.ReadCloser, error) {
+	switch r.Header.Get("Content-Encoding") {
+	case "", EncodingIdentity:
+		return r.Body, nil
+	case EncodingGzip:
+		return gzip.NewReader(r.Body)
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, xerrors.Errorf("unsupported content encoding %q", r.Header.Get("Content-Encoding"))
+	}
+}
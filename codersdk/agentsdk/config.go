@@ -0,0 +1,158 @@
+package agentsdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"golang.org/x/xerrors"
+	"nhooyr.io/websocket"
+
+	"cdr.dev/slog"
+	"github.com/coder/retry"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// AgentConfig is agent behavior that can be changed by the control plane
+// while the agent is running.
+type AgentConfig struct {
+	// Version increases every time the config changes. A config with the
+	// version of the last applied config is not applied again. Any other
+	// version is applied, as versions restart when the control plane does.
+	Version int64 `json:"version"`
+	// StatsSampleRate is the fraction of connections that stats are
+	// collected for, between 0 and 1.
+	StatsSampleRate float64 `json:"stats_sample_rate"`
+	// Features enables or disables optional agent features by name.
+	Features map[string]bool `json:"features"`
+}
+
+// FeatureEnabled returns true if the config enables the named feature.
+func (c AgentConfig) FeatureEnabled(name string) bool {
+	return c.Features[name]
+}
+
+// WatchConfig calls onConfig with the current config once connected, and
+// again every time the control plane pushes a new one. The watch reconnects
+// if the connection drops until ctx is canceled or the returned closer is
+// closed. onConfig is never called concurrently.
+func (c *Client) WatchConfig(
+	ctx context.Context,
+	log slog.Logger,
+	onConfig func(AgentConfig),
+) (io.Closer, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+		var version int64
+		// The retrier is shared by every reconnect, so a server that closes
+		// the watch right away is not reconnected to without backoff.
+		r := retry.New(100*time.Millisecond, time.Minute)
+		for {
+			var (
+				configs    <-chan AgentConfig
+				closeWatch io.Closer
+				err        error
+			)
+			for r.Wait(ctx) {
+				configs, closeWatch, err = c.dialConfigWatch(ctx)
+				if err != nil {
+					if !xerrors.Is(err, context.Canceled) {
+						log.Error(ctx, "watch agent config", slog.Error(err))
+					}
+					continue
+				}
+				break
+			}
+			if ctx.Err() != nil {
+				if closeWatch != nil {
+					_ = closeWatch.Close()
+				}
+				return
+			}
+			connectedAt := time.Now()
+			for config := range configs {
+				if config.Version != 0 && config.Version == version {
+					continue
+				}
+				version = config.Version
+				c.debug.recordConfig(config)
+				onConfig(config)
+			}
+			_ = closeWatch.Close()
+			if time.Since(connectedAt) > time.Minute {
+				// The watch was healthy, so reconnect quickly.
+				r = retry.New(100*time.Millisecond, time.Minute)
+			}
+		}
+	}()
+
+	return closeFunc(func() error {
+		cancel()
+		<-closed
+		return nil
+	}), nil
+}
+
+// dialConfigWatch connects to the config watch endpoint and decodes the
+// configs pushed by the server until the connection closes.
+func (c *Client) dialConfigWatch(ctx context.Context) (<-chan AgentConfig, io.Closer, error) {
+	watchURL, err := c.serverURL().Parse("/api/v2/workspaceagents/me/config/watch")
+	if err != nil {
+		return nil, nil, xerrors.Errorf("parse url: %w", err)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("create cookie jar: %w", err)
+	}
+	jar.SetCookies(watchURL, []*http.Cookie{{
+		Name:  codersdk.SessionTokenCookie,
+		Value: c.SDK.SessionToken(),
+	}})
+	httpClient := &http.Client{
+		Jar:       jar,
+		Transport: c.SDK.HTTPClient.Transport,
+	}
+	// nolint:bodyclose
+	conn, res, err := websocket.Dial(ctx, watchURL.String(), &websocket.DialOptions{
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		if res == nil {
+			return nil, nil, err
+		}
+		return nil, nil, codersdk.ReadBodyAsError(res)
+	}
+
+	configs := make(chan AgentConfig)
+	decoder := json.NewDecoder(websocket.NetConn(ctx, conn, websocket.MessageText))
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		defer close(configs)
+		defer conn.Close(websocket.StatusGoingAway, "")
+		for {
+			var config AgentConfig
+			err := decoder.Decode(&config)
+			if err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case configs <- config:
+			}
+		}
+	}()
+	return configs, closeFunc(func() error {
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+		<-closed
+		return nil
+	}), nil
+}
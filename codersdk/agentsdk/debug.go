@@ -0,0 +1,122 @@
+package agentsdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DebugState is a snapshot of the client's view of coderd, for operators
+// to inspect a misbehaving agent.
+type DebugState struct {
+	Health Health `json:"health"`
+	// Capabilities are the capabilities coderd acknowledged.
+	Capabilities []Capability `json:"capabilities"`
+	// Metadata summarizes the last metadata fetched from coderd, if any.
+	Metadata *DebugMetadata `json:"metadata,omitempty"`
+	// Stats are the last stats reported by ReportStats, if any.
+	Stats           *Stats    `json:"stats,omitempty"`
+	StatsReportedAt time.Time `json:"stats_reported_at"`
+	// Config is the last config received by WatchConfig, if any.
+	Config *AgentConfig `json:"config,omitempty"`
+}
+
+// DebugMetadata is the part of Metadata that is safe to show in the debug
+// state. Environment variables, the startup script and Git auth configs can
+// hold secrets, so they are only counted.
+type DebugMetadata struct {
+	Apps                 int          `json:"apps"`
+	DERPRegionIDs        []int        `json:"derp_region_ids"`
+	Directory            string       `json:"directory"`
+	GitAuthConfigs       int          `json:"git_auth_configs"`
+	EnvironmentVariables int          `json:"environment_variables"`
+	Capabilities         []Capability `json:"capabilities"`
+}
+
+func newDebugMetadata(metadata Metadata) *DebugMetadata {
+	debug := &DebugMetadata{
+		Apps:                 len(metadata.Apps),
+		Directory:            metadata.Directory,
+		GitAuthConfigs:       metadata.GitAuthConfigs,
+		EnvironmentVariables: len(metadata.EnvironmentVariables),
+		Capabilities:         metadata.Capabilities,
+	}
+	if metadata.DERPMap != nil {
+		for id := range metadata.DERPMap.Regions {
+			debug.DERPRegionIDs = append(debug.DERPRegionIDs, id)
+		}
+		sort.Ints(debug.DERPRegionIDs)
+	}
+	return debug
+}
+
+// debugState holds the last values seen by the client. The zero value is
+// ready to use.
+type debugState struct {
+	mu              sync.Mutex
+	metadata        *DebugMetadata
+	stats           *Stats
+	statsReportedAt time.Time
+	config          *AgentConfig
+}
+
+func (d *debugState) recordMetadata(metadata Metadata) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.metadata = newDebugMetadata(metadata)
+}
+
+func (d *debugState) recordStats(stats *Stats) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stats = stats
+	d.statsReportedAt = time.Now()
+}
+
+func (d *debugState) recordConfig(config AgentConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.config = &config
+}
+
+// DebugState returns a snapshot of the client's state.
+func (c *Client) DebugState() DebugState {
+	c.debug.mu.Lock()
+	state := DebugState{
+		Metadata:        c.debug.metadata,
+		Stats:           c.debug.stats,
+		StatsReportedAt: c.debug.statsReportedAt,
+		Config:          c.debug.config,
+	}
+	c.debug.mu.Unlock()
+
+	state.Health = c.Health()
+	for _, capability := range c.Capabilities {
+		if c.HasCapability(capability) {
+			state.Capabilities = append(state.Capabilities, capability)
+		}
+	}
+	return state
+}
+
+// DebugHandler returns a handler serving the client's state as JSON at
+// /debug/state. The state describes the workspace, eg its DERP regions, so
+// the handler responds with 404 unless EnableDebugState is set. Like
+// ReadinessHandler, the agent serves it on an address of its choosing.
+func (c *Client) DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/state", func(rw http.ResponseWriter, r *http.Request) {
+		if !c.EnableDebugState {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		rw.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "\t")
+		_ = enc.Encode(c.DebugState())
+	})
+	return mux
+}
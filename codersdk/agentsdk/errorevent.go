@@ -0,0 +1,82 @@
+package agentsdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// ErrorSeverity is how much a non-fatal agent error affects the workspace.
+type ErrorSeverity string
+
+const (
+	// ErrorSeverityWarning is an error the workspace works around, eg an
+	// optional mount that failed.
+	ErrorSeverityWarning ErrorSeverity = "warning"
+	// ErrorSeverityError is an error that leaves part of the workspace
+	// broken, eg a failed startup script.
+	ErrorSeverityError ErrorSeverity = "error"
+)
+
+// Valid returns whether the severity is known.
+func (s ErrorSeverity) Valid() bool {
+	switch s {
+	case ErrorSeverityWarning, ErrorSeverityError:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReportErrorTimeout bounds ReportError, so reporting never blocks the
+// agent for long.
+const ReportErrorTimeout = 5 * time.Second
+
+// ErrorEvent is a non-fatal error encountered by the agent, shown to the
+// user in the UI.
+type ErrorEvent struct {
+	// Code identifies the kind of error, eg "mount_failed".
+	Code     string        `json:"code"`
+	Message  string        `json:"message"`
+	Severity ErrorSeverity `json:"severity"`
+	// CreatedAt is when the error happened. It defaults to the time the
+	// event is reported. See Client.CorrectTimestamps.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportError tells the Coder server about a non-fatal error. It is
+// best-effort: the request is canceled after ReportErrorTimeout, or earlier
+// if the context is done, and callers should log a returned error rather
+// than fail.
+func (c *Client) ReportError(ctx context.Context, event ErrorEvent) error {
+	if event.Code == "" {
+		return xerrors.New("error code must not be empty")
+	}
+	if !event.Severity.Valid() {
+		return xerrors.Errorf("invalid error severity %q", event.Severity)
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if c.CorrectTimestamps {
+		event.CreatedAt = c.correctTime(event.CreatedAt)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ReportErrorTimeout)
+	defer cancel()
+	res, err := c.SDK.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/report-error", event)
+	c.health.observe(res, err)
+	c.clock.observe(res)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package agentsdk
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// DefaultFailoverRecheckInterval is how often the preferred URL is retried
+// after failing over to another URL.
+const DefaultFailoverRecheckInterval = 30 * time.Second
+
+// NewWithFailover returns a client that fails over between multiple coderd
+// URLs, such as in high availability deployments. The first URL is
+// preferred. Requests that fail to connect are retried on the next URL, as
+// are idempotent requests that fail after connecting. The URL that succeeded
+// is used for following requests. Once recheckInterval has passed since
+// failing over, requests try the preferred URL first again. A
+// recheckInterval <= 0 uses DefaultFailoverRecheckInterval.
+func NewWithFailover(serverURLs []*url.URL, recheckInterval time.Duration) (*Client, error) {
+	if len(serverURLs) == 0 {
+		return nil, xerrors.New("at least one server url is required")
+	}
+	if recheckInterval <= 0 {
+		recheckInterval = DefaultFailoverRecheckInterval
+	}
+	sdk := codersdk.New(serverURLs[0])
+	sdk.HTTPClient.Transport = &failoverTransport{
+		urls:    serverURLs,
+		recheck: recheckInterval,
+		next:    http.DefaultTransport,
+	}
+	return &Client{
+		SDK: sdk,
+	}, nil
+}
+
+// failoverTransport sends requests to the current URL, and fails over to
+// the next URL on connection errors. Requests are built against any of the
+// URLs, usually the first or the current one, and rewritten to the URL they
+// are sent to.
+type failoverTransport struct {
+	urls    []*url.URL
+	recheck time.Duration
+	next    http.RoundTripper
+
+	mu           sync.Mutex
+	current      int
+	failedOverAt time.Time
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	start := t.current
+	recheck := start != 0 && time.Since(t.failedOverAt) >= t.recheck
+	if recheck {
+		start = 0
+	}
+	t.mu.Unlock()
+
+	var lastErr error
+	for i := 0; i < len(t.urls); i++ {
+		index := (start + i) % len(t.urls)
+		attempt, err := t.rewrite(req, t.urls[index], i > 0)
+		if err != nil {
+			// The body cannot be sent again.
+			break
+		}
+		res, err := t.next.RoundTrip(attempt)
+		if err == nil {
+			t.succeeded(index, recheck || start == 0)
+			return res, nil
+		}
+		lastErr = err
+		if xerrors.Is(err, context.Canceled) || req.Context().Err() != nil {
+			break
+		}
+		if !retryable(req, err) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// retryable returns whether the request can be sent to the next URL after
+// failing with err. A request that may have reached the server is only
+// retried if it is idempotent, as sending it again could repeat its effect,
+// eg posting the same stats twice.
+func retryable(req *http.Request, err error) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	// Dial errors happen before the request is written.
+	var opErr *net.OpError
+	return xerrors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// succeeded sets the URL for following requests. triedPreferred is true if
+// the preferred URL was attempted, and restarts the recheck interval.
+func (t *failoverTransport) succeeded(index int, triedPreferred bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if index != 0 && triedPreferred {
+		t.failedOverAt = time.Now()
+	}
+	t.current = index
+}
+
+// currentURL returns the URL requests are currently sent to.
+func (t *failoverTransport) currentURL() *url.URL {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.urls[t.current]
+}
+
+// rewrite returns a copy of the request sent to the server URL. Retries need
+// a new copy of the body.
+func (t *failoverTransport) rewrite(req *http.Request, serverURL *url.URL, retry bool) (*http.Request, error) {
+	// Find the URL the request was built against, to replace its path.
+	base := t.urls[0]
+	for _, u := range t.urls {
+		if u.Scheme == req.URL.Scheme && u.Host == req.URL.Host {
+			base = u
+			break
+		}
+	}
+	attempt := req.Clone(req.Context())
+	attempt.URL.Scheme = serverURL.Scheme
+	attempt.URL.Host = serverURL.Host
+	attempt.URL.Path = strings.TrimSuffix(serverURL.Path, "/") + strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(base.Path, "/"))
+	attempt.Host = ""
+	if retry && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, xerrors.New("request body cannot be sent again")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, xerrors.Errorf("get body: %w", err)
+		}
+		attempt.Body = body
+	}
+	return attempt, nil
+}
+
+// serverURL returns the URL of the coderd the client currently sends
+// requests to. It is SDK.URL unless the client failed over to another URL.
+func (c *Client) serverURL() *url.URL {
+	if t, ok := c.SDK.HTTPClient.Transport.(*failoverTransport); ok {
+		return t.currentURL()
+	}
+	return c.SDK.URL
+}
@@ -0,0 +1,153 @@
+package agentsdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// Health is the agent's view of its connection to coderd.
+type Health string
+
+const (
+	HealthConnected    Health = "connected"
+	HealthDegraded     Health = "degraded"
+	HealthDisconnected Health = "disconnected"
+)
+
+const (
+	// HealthDegradedFailures is the number of consecutive failed requests
+	// after which the connection is considered degraded.
+	HealthDegradedFailures = 2
+	// HealthDisconnectedFailures is the number of consecutive failed
+	// requests after which the connection is considered disconnected.
+	HealthDisconnectedFailures = 5
+)
+
+// healthForFailures returns the health for a number of consecutive
+// failures.
+func healthForFailures(failures int) Health {
+	switch {
+	case failures >= HealthDisconnectedFailures:
+		return HealthDisconnected
+	case failures >= HealthDegradedFailures:
+		return HealthDegraded
+	default:
+		return HealthConnected
+	}
+}
+
+// healthTracker observes the outcome of requests to coderd. The zero
+// value is ready to use and starts connected.
+type healthTracker struct {
+	mu          sync.Mutex
+	failures    int
+	subscribers map[int]*healthSubscriber
+	nextID      int
+}
+
+// healthSubscriber queues transitions for a subscriber, so they are
+// delivered in the order they happened even when requests finish
+// concurrently.
+type healthSubscriber struct {
+	fn func(from, to Health)
+	// pending are the transitions not delivered yet, oldest first.
+	pending [][2]Health
+	// delivering is true while a goroutine is delivering the pending
+	// transitions. Only that goroutine calls fn.
+	delivering bool
+}
+
+// observe records the outcome of a request. Requests that fail to send or
+// that receive a server error are failures, any other response means coderd
+// is reachable. Canceled requests are ignored.
+func (h *healthTracker) observe(res *http.Response, err error) {
+	if xerrors.Is(err, context.Canceled) {
+		return
+	}
+	failed := err != nil || res.StatusCode >= http.StatusInternalServerError
+
+	h.mu.Lock()
+	from := healthForFailures(h.failures)
+	if failed {
+		h.failures++
+	} else {
+		h.failures = 0
+	}
+	to := healthForFailures(h.failures)
+	var deliver []*healthSubscriber
+	if from != to {
+		// Queue the transition under the lock, so each subscriber sees
+		// transitions in order.
+		for _, sub := range h.subscribers {
+			sub.pending = append(sub.pending, [2]Health{from, to})
+			if !sub.delivering {
+				sub.delivering = true
+				deliver = append(deliver, sub)
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	for _, sub := range deliver {
+		h.deliver(sub)
+	}
+}
+
+// deliver calls the subscriber with its pending transitions until none are
+// left. Transitions queued meanwhile by other goroutines are delivered
+// too, as those goroutines leave the delivery to this one.
+func (h *healthTracker) deliver(sub *healthSubscriber) {
+	for {
+		h.mu.Lock()
+		if len(sub.pending) == 0 {
+			sub.delivering = false
+			h.mu.Unlock()
+			return
+		}
+		transition := sub.pending[0]
+		sub.pending = sub.pending[1:]
+		h.mu.Unlock()
+
+		sub.fn(transition[0], transition[1])
+	}
+}
+
+func (h *healthTracker) health() Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return healthForFailures(h.failures)
+}
+
+func (h *healthTracker) subscribe(fn func(from, to Health)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[int]*healthSubscriber)
+	}
+	id := h.nextID
+	h.nextID++
+	sub := &healthSubscriber{fn: fn}
+	h.subscribers[id] = sub
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, id)
+		sub.pending = nil
+	}
+}
+
+// Health returns the connection health derived from the outcome of
+// Metadata and stats requests.
+func (c *Client) Health() Health {
+	return c.health.health()
+}
+
+// SubscribeHealth calls fn on every health transition, in the order the
+// transitions happened. Calls to fn are never concurrent. The returned
+// function removes the subscription.
+func (c *Client) SubscribeHealth(fn func(from, to Health)) func() {
+	return c.health.subscribe(fn)
+}
@@ -0,0 +1,86 @@
+package agentsdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// StartupLogSource identifies what produced a startup log.
+type StartupLogSource string
+
+const (
+	StartupLogSourceAgent         StartupLogSource = "agent"
+	StartupLogSourceStartupScript StartupLogSource = "startup_script"
+)
+
+// Valid returns whether the source is known.
+func (s StartupLogSource) Valid() bool {
+	switch s {
+	case StartupLogSourceAgent, StartupLogSourceStartupScript:
+		return true
+	default:
+		return false
+	}
+}
+
+// StartupLog is a single log line produced while the agent starts.
+type StartupLog struct {
+	CreatedAt time.Time         `json:"created_at"`
+	Output    string            `json:"output"`
+	Level     codersdk.LogLevel `json:"level"`
+	Source    StartupLogSource  `json:"source"`
+}
+
+// Validate ensures the log has a known level and source.
+func (l StartupLog) Validate() error {
+	switch l.Level {
+	case codersdk.LogLevelTrace, codersdk.LogLevelDebug, codersdk.LogLevelInfo,
+		codersdk.LogLevelWarn, codersdk.LogLevelError:
+	default:
+		return xerrors.Errorf("invalid log level %q", l.Level)
+	}
+	if !l.Source.Valid() {
+		return xerrors.Errorf("invalid log source %q", l.Source)
+	}
+	return nil
+}
+
+type PatchStartupLogs struct {
+	Logs []StartupLog `json:"logs"`
+}
+
+// PatchStartupLogs sends startup logs to the Coder server. All logs are
+// validated before anything is sent. Logs are sent in the order given, so
+// the ordering of logs within a source is preserved.
+func (c *Client) PatchStartupLogs(ctx context.Context, req PatchStartupLogs) error {
+	for i, log := range req.Logs {
+		if err := log.Validate(); err != nil {
+			return xerrors.Errorf("log %d: %w", i, err)
+		}
+	}
+	if c.CorrectTimestamps {
+		// Don't modify the caller's logs.
+		logs := make([]StartupLog, len(req.Logs))
+		for i, log := range req.Logs {
+			log.CreatedAt = c.correctTime(log.CreatedAt)
+			logs[i] = log
+		}
+		req.Logs = logs
+	}
+
+	res, err := c.compressedRequest(ctx, http.MethodPatch, "/api/v2/workspaceagents/me/startup-logs", req)
+	c.clock.observe(res)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package agentsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// DefaultLogChunkSize is the size of the chunks UploadLog sends when no
+// chunk size is given.
+const DefaultLogChunkSize = 1 << 20
+
+// LogUploadOffset is the number of bytes of a log the server has stored.
+type LogUploadOffset struct {
+	Offset int64 `json:"offset"`
+}
+
+// LogChunk is a part of a log starting at Offset. The server only accepts
+// a chunk that starts at the offset it has stored, so chunks are never
+// stored twice or with a gap between them.
+type LogChunk struct {
+	Offset int64  `json:"offset"`
+	Data   []byte `json:"data"`
+}
+
+func logUploadPath(name string) string {
+	return fmt.Sprintf("/api/v2/workspaceagents/me/log-uploads/%s", url.PathEscape(name))
+}
+
+// LogUploadOffset returns the offset the server has acknowledged for the log.
+// Uploads resume from this offset.
+func (c *Client) LogUploadOffset(ctx context.Context, name string) (LogUploadOffset, error) {
+	res, err := c.SDK.Request(ctx, http.MethodGet, logUploadPath(name), nil)
+	if err != nil {
+		return LogUploadOffset{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return LogUploadOffset{}, codersdk.ReadBodyAsError(res)
+	}
+	var offset LogUploadOffset
+	err = json.NewDecoder(res.Body).Decode(&offset)
+	if err != nil {
+		return LogUploadOffset{}, xerrors.Errorf("decode offset: %w", err)
+	}
+	return offset, nil
+}
+
+// PatchLogChunk sends a chunk of the log, and returns the offset the server
+// acknowledged after storing it.
+func (c *Client) PatchLogChunk(ctx context.Context, name string, chunk LogChunk) (LogUploadOffset, error) {
+	res, err := c.SDK.Request(ctx, http.MethodPatch, logUploadPath(name), chunk)
+	if err != nil {
+		return LogUploadOffset{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return LogUploadOffset{}, codersdk.ReadBodyAsError(res)
+	}
+	var offset LogUploadOffset
+	err = json.NewDecoder(res.Body).Decode(&offset)
+	if err != nil {
+		return LogUploadOffset{}, xerrors.Errorf("decode offset: %w", err)
+	}
+	return offset, nil
+}
+
+// UploadLog uploads the log in chunks of chunkSize bytes, starting at the
+// offset the server has acknowledged. If an upload is interrupted, eg by the
+// agent restarting, calling UploadLog again continues from the last
+// acknowledged byte instead of uploading the whole log again. A chunkSize
+// <= 0 uses DefaultLogChunkSize.
+func (c *Client) UploadLog(ctx context.Context, name string, log io.ReadSeeker, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultLogChunkSize
+	}
+	ack, err := c.LogUploadOffset(ctx, name)
+	if err != nil {
+		return xerrors.Errorf("get offset: %w", err)
+	}
+	offset := ack.Offset
+	_, err = log.Seek(offset, io.SeekStart)
+	if err != nil {
+		return xerrors.Errorf("seek to offset %d: %w", offset, err)
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, readErr := io.ReadFull(log, buf)
+		if n > 0 {
+			ack, err := c.PatchLogChunk(ctx, name, LogChunk{
+				Offset: offset,
+				Data:   buf[:n],
+			})
+			if err != nil {
+				return xerrors.Errorf("upload chunk at offset %d: %w", offset, err)
+			}
+			if ack.Offset != offset+int64(n) {
+				return xerrors.Errorf("server acknowledged offset %d, expected %d", ack.Offset, offset+int64(n))
+			}
+			offset = ack.Offset
+		}
+		if xerrors.Is(readErr, io.EOF) || xerrors.Is(readErr, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if readErr != nil {
+			return xerrors.Errorf("read log: %w", readErr)
+		}
+	}
+}
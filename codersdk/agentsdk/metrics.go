@@ -0,0 +1,138 @@
+package agentsdk
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// clientMetrics are collected for every request made by the client once
+// registered.
+type clientMetrics struct {
+	requests     *prometheus.CounterVec
+	latencies    *prometheus.HistogramVec
+	statsReports *prometheus.CounterVec
+}
+
+// RegisterMetrics registers request counts, request latencies and stats
+// report results with the registerer. Metrics are only collected after
+// registering, which must happen before the client is used.
+func (c *Client) RegisterMetrics(registerer prometheus.Registerer) {
+	factory := promauto.With(registerer)
+	c.metrics = &clientMetrics{
+		requests: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coder",
+			Subsystem: "agentsdk",
+			Name:      "requests_total",
+			Help:      "The total number of requests made to coderd.",
+		}, []string{"code", "method", "path"}),
+		latencies: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "coder",
+			Subsystem: "agentsdk",
+			Name:      "request_latencies_seconds",
+			Help:      "Latency distribution of requests to coderd in seconds.",
+			Buckets:   []float64{0.001, 0.005, 0.010, 0.025, 0.050, 0.100, 0.500, 1, 5, 10, 30},
+		}, []string{"method", "path"}),
+		statsReports: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coder",
+			Subsystem: "agentsdk",
+			Name:      "stats_reports_total",
+			Help:      "The total number of stats reports by result.",
+		}, []string{"result"}),
+	}
+
+	transport := c.SDK.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c.SDK.HTTPClient.Transport = &metricsTransport{
+		metrics: c.metrics,
+		next:    transport,
+	}
+}
+
+// observeStatsReport records the result of a stats report. It is a noop
+// if metrics are not registered.
+func (m *clientMetrics) observeStatsReport(err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.statsReports.WithLabelValues(result).Inc()
+}
+
+// MetricsHandler serves the metrics gathered by the gatherer in the
+// Prometheus text format. Agents can use this to serve /metrics locally.
+func MetricsHandler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
+// metricsTransport records the code and latency of every request.
+type metricsTransport struct {
+	metrics *clientMetrics
+	next    http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	path := metricsPath(req.URL.Path)
+	t.metrics.requests.WithLabelValues(code, req.Method, path).Inc()
+	t.metrics.latencies.WithLabelValues(req.Method, path).Observe(time.Since(start).Seconds())
+	return res, err
+}
+
+// metricsRoutes are the routes the client requests. Routes ending in a
+// parameter match any single path element in its place.
+var metricsRoutes = []string{
+	"/api/v2/workspaceagents/aws-instance-identity",
+	"/api/v2/workspaceagents/azure-instance-identity",
+	"/api/v2/workspaceagents/google-instance-identity",
+	"/api/v2/workspaceagents/me/app-health",
+	"/api/v2/workspaceagents/me/config/watch",
+	"/api/v2/workspaceagents/me/coordinate",
+	"/api/v2/workspaceagents/me/gitauth",
+	"/api/v2/workspaceagents/me/gitsshkey",
+	"/api/v2/workspaceagents/me/log-uploads/{name}",
+	"/api/v2/workspaceagents/me/metadata",
+	"/api/v2/workspaceagents/me/report-error",
+	"/api/v2/workspaceagents/me/report-lifecycle",
+	"/api/v2/workspaceagents/me/report-shutdown",
+	"/api/v2/workspaceagents/me/report-stats",
+	"/api/v2/workspaceagents/me/report-stats-delta",
+	"/api/v2/workspaceagents/me/startup-logs",
+	"/api/v2/workspaceagents/me/version",
+}
+
+// metricsPath returns the route template of the request path, so the path
+// label has a fixed set of values. Any path prefix of the access URL is
+// ignored, and unknown paths are labeled "other".
+func metricsPath(path string) string {
+	if i := strings.Index(path, "/api/v2/"); i > 0 {
+		path = path[i:]
+	}
+	for _, route := range metricsRoutes {
+		if route == path {
+			return route
+		}
+		if i := strings.Index(route, "/{"); i >= 0 {
+			prefix := route[:i+1]
+			if strings.HasPrefix(path, prefix) && len(path) > len(prefix) && !strings.Contains(path[len(prefix):], "/") {
+				return route
+			}
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,47 @@
+package agentsdk
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// Readiness is the response of the readiness probe.
+type Readiness struct {
+	// Ready is true if the agent has finished starting and can reach
+	// coderd.
+	Ready     bool                             `json:"ready"`
+	Lifecycle codersdk.WorkspaceAgentLifecycle `json:"lifecycle"`
+	Health    Health                           `json:"health"`
+	// Stats are the agent's current stats, if provided.
+	Stats *Stats `json:"stats,omitempty"`
+}
+
+// ReadinessHandler returns a handler for the control plane to probe the
+// agent directly. It responds with the agent's readiness, with a 200 status
+// if the agent is ready and 503 otherwise. The agent is ready when its
+// lifecycle is ready and its connection to coderd is not disconnected.
+//
+// The probe is opt-in, the agent serves the handler on an address of its
+// choosing. stats may be nil to omit stats from the response.
+func (c *Client) ReadinessHandler(lifecycle func() codersdk.WorkspaceAgentLifecycle, stats func() *Stats) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		readiness := Readiness{
+			Lifecycle: lifecycle(),
+			Health:    c.Health(),
+		}
+		readiness.Ready = readiness.Lifecycle == codersdk.WorkspaceAgentLifecycleReady && readiness.Health != HealthDisconnected
+		if stats != nil {
+			readiness.Stats = stats()
+		}
+
+		status := http.StatusOK
+		if !readiness.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		rw.WriteHeader(status)
+		_ = json.NewEncoder(rw).Encode(readiness)
+	})
+}
@@ -0,0 +1,63 @@
+package agentsdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// ShutdownReason is why the agent is shutting down.
+type ShutdownReason string
+
+const (
+	ShutdownReasonUserRequested ShutdownReason = "user_requested"
+	ShutdownReasonTemplateStop  ShutdownReason = "template_stop"
+	ShutdownReasonError         ShutdownReason = "error"
+	ShutdownReasonTimeout       ShutdownReason = "timeout"
+)
+
+// Valid returns whether the reason is known.
+func (r ShutdownReason) Valid() bool {
+	switch r {
+	case ShutdownReasonUserRequested, ShutdownReasonTemplateStop,
+		ShutdownReasonError, ShutdownReasonTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReportShutdownTimeout bounds ReportShutdown, so reporting never delays
+// the agent from shutting down.
+const ReportShutdownTimeout = 5 * time.Second
+
+type ReportShutdownRequest struct {
+	Reason ShutdownReason `json:"reason"`
+	// Message optionally describes the reason, eg the error.
+	Message string `json:"message,omitempty"`
+}
+
+// ReportShutdown tells the Coder server why the agent is shutting down. It
+// is best-effort and meant to be called during teardown: the request is
+// canceled after ReportShutdownTimeout, or earlier if the context is done.
+func (c *Client) ReportShutdown(ctx context.Context, req ReportShutdownRequest) error {
+	if !req.Reason.Valid() {
+		return xerrors.Errorf("invalid shutdown reason %q", req.Reason)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ReportShutdownTimeout)
+	defer cancel()
+	res, err := c.SDK.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/report-shutdown", req)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
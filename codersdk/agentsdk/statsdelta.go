@@ -0,0 +1,135 @@
+package agentsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// StatsDelta is the fields of Stats that changed since the last full
+// report. Unchanged fields are omitted, and changed fields hold their
+// current value. Deltas are always relative to the last full report, so a
+// lost delta does not affect the next one.
+type StatsDelta struct {
+	// ConnsByProto has the protocols with a changed count. Protocols that
+	// are no longer in the stats have a count of 0.
+	ConnsByProto map[string]int64 `json:"conns_by_proto,omitempty"`
+	NumConns     *int64           `json:"num_comms,omitempty"`
+	RxPackets    *int64           `json:"rx_packets,omitempty"`
+	RxBytes      *int64           `json:"rx_bytes,omitempty"`
+	TxPackets    *int64           `json:"tx_packets,omitempty"`
+	TxBytes      *int64           `json:"tx_bytes,omitempty"`
+
+	SessionCountSSH             *int64 `json:"session_count_ssh,omitempty"`
+	SessionCountReconnectingPTY *int64 `json:"session_count_reconnecting_pty,omitempty"`
+	SessionCountPortForward     *int64 `json:"session_count_port_forward,omitempty"`
+
+	// Processes are always sent if enabled, as they change every report.
+	Processes []ProcessStats `json:"processes,omitempty"`
+}
+
+// StatsDeltaFrom returns the fields of current that differ from base.
+func StatsDeltaFrom(base, current *Stats) StatsDelta {
+	changed := func(base, current int64) *int64 {
+		if base == current {
+			return nil
+		}
+		return &current
+	}
+	delta := StatsDelta{
+		NumConns:                    changed(base.NumConns, current.NumConns),
+		RxPackets:                   changed(base.RxPackets, current.RxPackets),
+		RxBytes:                     changed(base.RxBytes, current.RxBytes),
+		TxPackets:                   changed(base.TxPackets, current.TxPackets),
+		TxBytes:                     changed(base.TxBytes, current.TxBytes),
+		SessionCountSSH:             changed(base.SessionCountSSH, current.SessionCountSSH),
+		SessionCountReconnectingPTY: changed(base.SessionCountReconnectingPTY, current.SessionCountReconnectingPTY),
+		SessionCountPortForward:     changed(base.SessionCountPortForward, current.SessionCountPortForward),
+		Processes:                   current.Processes,
+	}
+	for proto, count := range current.ConnsByProto {
+		if base.ConnsByProto[proto] != count {
+			if delta.ConnsByProto == nil {
+				delta.ConnsByProto = make(map[string]int64)
+			}
+			delta.ConnsByProto[proto] = count
+		}
+	}
+	for proto := range base.ConnsByProto {
+		if _, ok := current.ConnsByProto[proto]; !ok {
+			if delta.ConnsByProto == nil {
+				delta.ConnsByProto = make(map[string]int64)
+			}
+			delta.ConnsByProto[proto] = 0
+		}
+	}
+	return delta
+}
+
+// Apply returns the stats reconstructed from the last full report and the
+// delta.
+func (d StatsDelta) Apply(base Stats) Stats {
+	set := func(field *int64, value *int64) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	set(&base.NumConns, d.NumConns)
+	set(&base.RxPackets, d.RxPackets)
+	set(&base.RxBytes, d.RxBytes)
+	set(&base.TxPackets, d.TxPackets)
+	set(&base.TxBytes, d.TxBytes)
+	set(&base.SessionCountSSH, d.SessionCountSSH)
+	set(&base.SessionCountReconnectingPTY, d.SessionCountReconnectingPTY)
+	set(&base.SessionCountPortForward, d.SessionCountPortForward)
+	if d.Processes != nil {
+		base.Processes = d.Processes
+	}
+
+	if len(d.ConnsByProto) > 0 {
+		conns := make(map[string]int64, len(base.ConnsByProto))
+		for proto, count := range base.ConnsByProto {
+			conns[proto] = count
+		}
+		for proto, count := range d.ConnsByProto {
+			if count == 0 {
+				delete(conns, proto)
+				continue
+			}
+			conns[proto] = count
+		}
+		base.ConnsByProto = conns
+	}
+	return base
+}
+
+// PostStatsDelta sends the stats that changed since the last full report
+// sent with PostStats.
+func (c *Client) PostStatsDelta(ctx context.Context, delta StatsDelta) (StatsResponse, error) {
+	res, err := c.compressedRequest(ctx, http.MethodPost, "/api/v2/workspaceagents/me/report-stats-delta", delta)
+	c.health.observe(res, err)
+	c.clock.observe(res)
+	if err != nil {
+		c.metrics.observeStatsReport(err)
+		return StatsResponse{}, xerrors.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		err = codersdk.ReadBodyAsError(res)
+		c.metrics.observeStatsReport(err)
+		return StatsResponse{}, err
+	}
+	c.metrics.observeStatsReport(nil)
+
+	var interval StatsResponse
+	err = json.NewDecoder(res.Body).Decode(&interval)
+	if err != nil {
+		return StatsResponse{}, xerrors.Errorf("decode stats response: %w", err)
+	}
+
+	return interval, nil
+}
@@ -99,7 +99,7 @@ type AuditLog struct {
 	Action           AuditAction     `json:"action"`
 	Diff             AuditDiff       `json:"diff"`
 	StatusCode       int32           `json:"status_code"`
-	AdditionalFields json.RawMessage `json:"additional_fields"`
+	AdditionalFields json.RawMessage `json:"additional_fields" typescript:"Record<string, string>"`
 	Description      string          `json:"description"`
 	ResourceLink     string          `json:"resource_link"`
 	IsDeleted        bool            `json:"is_deleted"`
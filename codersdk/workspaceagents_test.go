@@ -1,20 +1,29 @@
 package codersdk_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
 	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/testutil"
 )
@@ -78,3 +87,1294 @@ func TestAgentReportStats(t *testing.T) {
 		testutil.WaitMedium, testutil.IntervalFast,
 	)
 }
+
+func TestAgentReportStatsDelta(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		paths   []string
+		full    agentsdk.Stats
+		current []agentsdk.Stats
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v2/workspaceagents/me/report-stats":
+			var stats agentsdk.Stats
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&stats))
+			full = stats
+			current = append(current, stats)
+		case "/api/v2/workspaceagents/me/report-stats-delta":
+			var delta agentsdk.StatsDelta
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&delta))
+			// Only the changed fields are sent.
+			assert.Nil(t, delta.TxBytes)
+			assert.NotNil(t, delta.RxBytes)
+			current = append(current, delta.Apply(full))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{
+			ReportInterval: 5 * time.Millisecond,
+		})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+	client.StatsFullReportEvery = 3
+
+	var rxBytes atomic.Int64
+	ctx := context.Background()
+	closeStream, err := client.ReportStats(ctx, slogtest.Make(t, nil), func() *agentsdk.Stats {
+		return &agentsdk.Stats{
+			ConnsByProto: map[string]int64{"tcp": 1},
+			RxBytes:      rxBytes.Add(1),
+			TxBytes:      10,
+		}
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(paths) >= 7
+	}, testutil.WaitMedium, testutil.IntervalFast)
+	require.NoError(t, closeStream.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{
+		"/api/v2/workspaceagents/me/report-stats",
+		"/api/v2/workspaceagents/me/report-stats-delta",
+		"/api/v2/workspaceagents/me/report-stats-delta",
+		"/api/v2/workspaceagents/me/report-stats",
+		"/api/v2/workspaceagents/me/report-stats-delta",
+		"/api/v2/workspaceagents/me/report-stats-delta",
+		"/api/v2/workspaceagents/me/report-stats",
+	}, paths[:7])
+	// The server reconstructs the full stats from the deltas.
+	for i, stats := range current[:7] {
+		require.Equal(t, agentsdk.Stats{
+			ConnsByProto: map[string]int64{"tcp": 1},
+			RxBytes:      int64(i + 1),
+			TxBytes:      10,
+		}, stats)
+	}
+}
+
+func TestAgentReportStatsDeltaFallback(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu    sync.Mutex
+			paths []string
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			paths = append(paths, r.URL.Path)
+			if r.URL.Path != "/api/v2/workspaceagents/me/report-stats" {
+				// Older versions of coderd don't have the delta endpoint.
+				httpapi.ResourceNotFound(w)
+				return
+			}
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{
+				ReportInterval: 5 * time.Millisecond,
+			})
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+		client.StatsFullReportEvery = 3
+
+		var rxBytes atomic.Int64
+		closeStream, err := client.ReportStats(context.Background(), slogtest.Make(t, nil), func() *agentsdk.Stats {
+			return &agentsdk.Stats{RxBytes: rxBytes.Add(1)}
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(paths) >= 5
+		}, testutil.WaitMedium, testutil.IntervalFast)
+		require.NoError(t, closeStream.Close())
+
+		mu.Lock()
+		defer mu.Unlock()
+		// The rejected delta is sent again as a full report, and no more
+		// deltas are sent.
+		require.Equal(t, []string{
+			"/api/v2/workspaceagents/me/report-stats",
+			"/api/v2/workspaceagents/me/report-stats-delta",
+			"/api/v2/workspaceagents/me/report-stats",
+			"/api/v2/workspaceagents/me/report-stats",
+			"/api/v2/workspaceagents/me/report-stats",
+		}, paths[:5])
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu     sync.Mutex
+			paths  []string
+			failed bool
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			paths = append(paths, r.URL.Path)
+			if r.URL.Path == "/api/v2/workspaceagents/me/report-stats-delta" && !failed {
+				// The server may or may not have applied the delta.
+				failed = true
+				httpapi.Write(context.Background(), w, http.StatusInternalServerError, codersdk.Response{Message: "oops"})
+				return
+			}
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{
+				ReportInterval: 5 * time.Millisecond,
+			})
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+		client.StatsFullReportEvery = 3
+
+		var rxBytes atomic.Int64
+		closeStream, err := client.ReportStats(context.Background(), slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), func() *agentsdk.Stats {
+			return &agentsdk.Stats{RxBytes: rxBytes.Add(1)}
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(paths) >= 4
+		}, testutil.WaitMedium, testutil.IntervalFast)
+		require.NoError(t, closeStream.Close())
+
+		mu.Lock()
+		defer mu.Unlock()
+		// The failed delta is retried as a full report.
+		require.Equal(t, []string{
+			"/api/v2/workspaceagents/me/report-stats",
+			"/api/v2/workspaceagents/me/report-stats-delta",
+			"/api/v2/workspaceagents/me/report-stats",
+			"/api/v2/workspaceagents/me/report-stats-delta",
+		}, paths[:4])
+	})
+}
+
+func TestAgentReportStatsProcesses(t *testing.T) {
+	t.Parallel()
+
+	report := func(t *testing.T, processes func() []agentsdk.ProcessStats) map[string]json.RawMessage {
+		t.Helper()
+		reports := make(chan map[string]json.RawMessage, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]json.RawMessage
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			select {
+			case reports <- body:
+			default:
+			}
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{
+				ReportInterval: time.Minute,
+			})
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+		client.ProcessStats = processes
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+		closeStream, err := client.ReportStats(ctx, slogtest.Make(t, nil), func() *agentsdk.Stats {
+			return &agentsdk.Stats{NumConns: 1}
+		})
+		require.NoError(t, err)
+		defer closeStream.Close()
+
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for stats")
+			return nil
+		case body := <-reports:
+			return body
+		}
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		body := report(t, nil)
+		require.NotContains(t, body, "processes")
+		// The payload is unchanged for servers that do not know about
+		// processes.
+		require.JSONEq(t, "1", string(body["num_comms"]))
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Parallel()
+		body := report(t, func() []agentsdk.ProcessStats {
+			return []agentsdk.ProcessStats{{PID: 42, Name: "code-server", CPUSeconds: 1.5, RSSBytes: 1 << 20}}
+		})
+		var processes []agentsdk.ProcessStats
+		require.NoError(t, json.Unmarshal(body["processes"], &processes))
+		require.Equal(t, []agentsdk.ProcessStats{{PID: 42, Name: "code-server", CPUSeconds: 1.5, RSSBytes: 1 << 20}}, processes)
+		require.JSONEq(t, "1", string(body["num_comms"]))
+	})
+}
+
+func TestStatsDelta(t *testing.T) {
+	t.Parallel()
+
+	base := agentsdk.Stats{
+		ConnsByProto: map[string]int64{"tcp": 2, "udp": 1},
+		NumConns:     3,
+		RxBytes:      100,
+	}
+	current := agentsdk.Stats{
+		ConnsByProto: map[string]int64{"tcp": 2, "quic": 4},
+		NumConns:     3,
+		RxBytes:      150,
+	}
+	delta := agentsdk.StatsDeltaFrom(&base, &current)
+	require.Nil(t, delta.NumConns)
+	require.Equal(t, map[string]int64{"udp": 0, "quic": 4}, delta.ConnsByProto)
+	require.Equal(t, current, delta.Apply(base))
+	require.Equal(t, map[string]int64{"tcp": 2, "udp": 1}, base.ConnsByProto, "base is not modified")
+
+	data, err := json.Marshal(agentsdk.StatsDeltaFrom(&current, &current))
+	require.NoError(t, err)
+	require.JSONEq(t, "{}", string(data))
+}
+
+func TestAgentReportStatsSessionCounts(t *testing.T) {
+	t.Parallel()
+
+	reports := make(chan map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Decode into a map, like a server that does not know the fields.
+		var report map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&report)
+		if err != nil {
+			httpapi.Write(context.Background(), w, http.StatusBadRequest, codersdk.Response{
+				Message: err.Error(),
+			})
+			return
+		}
+		select {
+		case reports <- report:
+		default:
+		}
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{
+			ReportInterval: time.Minute,
+		})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+	closeStream, err := client.ReportStats(ctx, slogtest.Make(t, nil), func() *agentsdk.Stats {
+		return &agentsdk.Stats{
+			NumConns:                    4,
+			SessionCountSSH:             2,
+			SessionCountReconnectingPTY: 1,
+			SessionCountPortForward:     3,
+		}
+	})
+	require.NoError(t, err)
+	defer closeStream.Close()
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for stats")
+	case report := <-reports:
+		require.EqualValues(t, 4, report["num_comms"])
+		require.EqualValues(t, 2, report["session_count_ssh"])
+		require.EqualValues(t, 1, report["session_count_reconnecting_pty"])
+		require.EqualValues(t, 3, report["session_count_port_forward"])
+	}
+}
+
+func TestAgentHealth(t *testing.T) {
+	t.Parallel()
+
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			httpapi.InternalServerError(w, nil)
+			return
+		}
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+	require.Equal(t, agentsdk.HealthConnected, client.Health())
+
+	var transitions [][2]agentsdk.Health
+	unsubscribe := client.SubscribeHealth(func(from, to agentsdk.Health) {
+		transitions = append(transitions, [2]agentsdk.Health{from, to})
+	})
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	failing.Store(true)
+	for i := 0; i < agentsdk.HealthDisconnectedFailures; i++ {
+		_, err = client.PostStats(ctx, &agentsdk.Stats{})
+		require.Error(t, err)
+	}
+	require.Equal(t, agentsdk.HealthDisconnected, client.Health())
+
+	failing.Store(false)
+	_, err = client.PostStats(ctx, &agentsdk.Stats{})
+	require.NoError(t, err)
+	require.Equal(t, agentsdk.HealthConnected, client.Health())
+
+	require.Equal(t, [][2]agentsdk.Health{
+		{agentsdk.HealthConnected, agentsdk.HealthDegraded},
+		{agentsdk.HealthDegraded, agentsdk.HealthDisconnected},
+		{agentsdk.HealthDisconnected, agentsdk.HealthConnected},
+	}, transitions)
+}
+
+// TestAgentHealthOrder ensures subscribers receive transitions in the order
+// they happened when requests finish concurrently.
+func TestAgentHealthOrder(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail runs of requests, so the health goes up and down.
+		if requests.Add(1)%8 < agentsdk.HealthDisconnectedFailures {
+			httpapi.InternalServerError(w, nil)
+			return
+		}
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+
+	var transitions [][2]agentsdk.Health
+	unsubscribe := client.SubscribeHealth(func(from, to agentsdk.Health) {
+		transitions = append(transitions, [2]agentsdk.Health{from, to})
+	})
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				_, _ = client.PostStats(ctx, &agentsdk.Stats{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Each transition starts from the health the previous one ended at.
+	previous := agentsdk.HealthConnected
+	for _, transition := range transitions {
+		require.Equal(t, previous, transition[0], "transitions %v", transitions)
+		previous = transition[1]
+	}
+	require.Equal(t, client.Health(), previous)
+}
+
+func TestAgentReadiness(t *testing.T) {
+	t.Parallel()
+
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			httpapi.InternalServerError(w, nil)
+			return
+		}
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+
+	var lifecycle atomic.Value
+	lifecycle.Store(codersdk.WorkspaceAgentLifecycleStarting)
+	probe := httptest.NewServer(client.ReadinessHandler(func() codersdk.WorkspaceAgentLifecycle {
+		return lifecycle.Load().(codersdk.WorkspaceAgentLifecycle)
+	}, func() *agentsdk.Stats {
+		return &agentsdk.Stats{NumConns: 2}
+	}))
+	defer probe.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	probeReadiness := func(t *testing.T, status int) agentsdk.Readiness {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.URL, nil)
+		require.NoError(t, err)
+		res, err := probe.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, status, res.StatusCode)
+		var readiness agentsdk.Readiness
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&readiness))
+		return readiness
+	}
+
+	readiness := probeReadiness(t, http.StatusServiceUnavailable)
+	require.False(t, readiness.Ready)
+	require.Equal(t, codersdk.WorkspaceAgentLifecycleStarting, readiness.Lifecycle)
+	require.Equal(t, agentsdk.HealthConnected, readiness.Health)
+	require.Equal(t, int64(2), readiness.Stats.NumConns)
+
+	lifecycle.Store(codersdk.WorkspaceAgentLifecycleReady)
+	readiness = probeReadiness(t, http.StatusOK)
+	require.True(t, readiness.Ready)
+	require.Equal(t, codersdk.WorkspaceAgentLifecycleReady, readiness.Lifecycle)
+
+	// An agent that cannot reach coderd is not ready.
+	failing.Store(true)
+	for i := 0; i < agentsdk.HealthDisconnectedFailures; i++ {
+		_, err = client.PostStats(ctx, &agentsdk.Stats{})
+		require.Error(t, err)
+	}
+	readiness = probeReadiness(t, http.StatusServiceUnavailable)
+	require.False(t, readiness.Ready)
+	require.Equal(t, agentsdk.HealthDisconnected, readiness.Health)
+}
+
+func TestAgentDebugState(t *testing.T) {
+	t.Parallel()
+
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			httpapi.InternalServerError(w, nil)
+			return
+		}
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.Metadata{
+			DERPMap: &tailcfg.DERPMap{
+				Regions: map[int]*tailcfg.DERPRegion{
+					999: {RegionID: 999, RegionCode: "test", RegionName: "Test"},
+				},
+			},
+			EnvironmentVariables: map[string]string{"GITHUB_TOKEN": "secret-token"},
+			StartupScript:        "echo secret-script",
+			Directory:            "/home/coder",
+		})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	getState := func(t *testing.T, handler http.Handler, status int) agentsdk.DebugState {
+		t.Helper()
+		debug := httptest.NewServer(handler)
+		defer debug.Close()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, debug.URL+"/debug/state", nil)
+		require.NoError(t, err)
+		res, err := debug.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, status, res.StatusCode)
+		var state agentsdk.DebugState
+		if status == http.StatusOK {
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			// Secrets in the metadata are never shown.
+			require.NotContains(t, string(body), "secret")
+			require.NoError(t, json.Unmarshal(body, &state))
+		}
+		return state
+	}
+
+	// The endpoint is disabled by default.
+	getState(t, agentsdk.New(parsed).DebugHandler(), http.StatusNotFound)
+
+	client := agentsdk.New(parsed)
+	client.EnableDebugState = true
+	state := getState(t, client.DebugHandler(), http.StatusOK)
+	require.Nil(t, state.Metadata)
+	require.Equal(t, agentsdk.HealthConnected, state.Health)
+
+	_, err = client.Metadata(ctx)
+	require.NoError(t, err)
+	failing.Store(true)
+	for i := 0; i < agentsdk.HealthDisconnectedFailures; i++ {
+		_, err = client.Metadata(ctx)
+		require.Error(t, err)
+	}
+
+	state = getState(t, client.DebugHandler(), http.StatusOK)
+	require.Equal(t, agentsdk.HealthDisconnected, state.Health)
+	require.NotNil(t, state.Metadata)
+	require.Equal(t, []int{999}, state.Metadata.DERPRegionIDs)
+	require.Equal(t, "/home/coder", state.Metadata.Directory)
+	require.Equal(t, 1, state.Metadata.EnvironmentVariables)
+}
+
+func TestAgentMetrics(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+	registry := prometheus.NewRegistry()
+	client.RegisterMetrics(registry)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		_, err = client.PostStats(ctx, &agentsdk.Stats{})
+		require.NoError(t, err)
+	}
+	// Paths with parameters are labeled by their route.
+	for _, name := range []string{"first.log", "second.log"} {
+		_, err = client.LogUploadOffset(ctx, name)
+		require.NoError(t, err)
+	}
+
+	rec := httptest.NewRecorder()
+	agentsdk.MetricsHandler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, `coder_agentsdk_requests_total{code="200",method="POST",path="/api/v2/workspaceagents/me/report-stats"} 2`)
+	require.Contains(t, body, `coder_agentsdk_request_latencies_seconds_count{method="POST",path="/api/v2/workspaceagents/me/report-stats"} 2`)
+	require.Contains(t, body, `coder_agentsdk_requests_total{code="200",method="GET",path="/api/v2/workspaceagents/me/log-uploads/{name}"} 2`)
+	require.NotContains(t, body, "first.log")
+	require.Contains(t, body, `coder_agentsdk_stats_reports_total{result="success"} 2`)
+}
+
+func TestAgentFailover(t *testing.T) {
+	t.Parallel()
+
+	var down atomic.Bool
+	var firstRequests, secondRequests atomic.Int64
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			// Close the connection without a response.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		firstRequests.Add(1)
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{})
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secondRequests.Add(1)
+		if r.URL.Path == "/api/v2/workspaceagents/me/metadata" {
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.Metadata{
+				DERPMap: &tailcfg.DERPMap{
+					Regions: map[int]*tailcfg.DERPRegion{
+						1: {
+							EmbeddedRelay: true,
+							Nodes:         []*tailcfg.DERPNode{{HostName: "global.example.com"}},
+						},
+					},
+				},
+			})
+			return
+		}
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{})
+	}))
+	defer second.Close()
+
+	firstURL, err := url.Parse(first.URL)
+	require.NoError(t, err)
+	secondURL, err := url.Parse(second.URL)
+	require.NoError(t, err)
+	const recheck = 50 * time.Millisecond
+	client, err := agentsdk.NewWithFailover([]*url.URL{firstURL, secondURL}, recheck)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	down.Store(true)
+	// The stats may have reached the server, so they are not sent again.
+	_, err = client.PostStats(ctx, &agentsdk.Stats{})
+	require.Error(t, err)
+	require.EqualValues(t, 0, secondRequests.Load())
+
+	// Reading metadata is idempotent, so it is retried on the next server.
+	// The built-in DERP points at the server the client failed over to.
+	metadata, err := client.Metadata(ctx)
+	require.NoError(t, err)
+	node := metadata.DERPMap.Regions[1].Nodes[0]
+	require.Equal(t, secondURL.Hostname(), node.HostName)
+	require.Equal(t, secondURL.Port(), strconv.Itoa(node.DERPPort))
+	require.True(t, node.ForceHTTP)
+
+	// Following requests are sent to the server the client failed over to.
+	_, err = client.PostStats(ctx, &agentsdk.Stats{})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, firstRequests.Load())
+	require.EqualValues(t, 2, secondRequests.Load())
+
+	// Fail back once the first server recovers and the recheck interval
+	// has passed.
+	down.Store(false)
+	time.Sleep(recheck)
+	_, err = client.PostStats(ctx, &agentsdk.Stats{})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, firstRequests.Load())
+	require.EqualValues(t, 2, secondRequests.Load())
+
+	// Any request is retried if the server could not be dialed, as it
+	// never received the request.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL, err := url.Parse(closed.URL)
+	require.NoError(t, err)
+	closed.Close()
+	client, err = agentsdk.NewWithFailover([]*url.URL{closedURL, secondURL}, recheck)
+	require.NoError(t, err)
+	_, err = client.PostStats(ctx, &agentsdk.Stats{})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, secondRequests.Load())
+
+	_, err = agentsdk.NewWithFailover(nil, recheck)
+	require.Error(t, err)
+}
+
+func TestAgentReportShutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		var received agentsdk.ReportShutdownRequest
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !httpapi.Read(r.Context(), w, r, &received) {
+				return
+			}
+			httpapi.Write(r.Context(), w, http.StatusNoContent, nil)
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err = client.ReportShutdown(ctx, agentsdk.ReportShutdownRequest{
+			Reason:  agentsdk.ShutdownReasonError,
+			Message: "startup script failed",
+		})
+		require.NoError(t, err)
+		require.Equal(t, agentsdk.ShutdownReasonError, received.Reason)
+		require.Equal(t, "startup script failed", received.Message)
+	})
+
+	t.Run("InvalidReason", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int64
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			httpapi.Write(r.Context(), w, http.StatusNoContent, nil)
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err = client.ReportShutdown(ctx, agentsdk.ReportShutdownRequest{})
+		require.ErrorContains(t, err, "invalid shutdown reason")
+		err = client.ReportShutdown(ctx, agentsdk.ReportShutdownRequest{Reason: "crashed"})
+		require.ErrorContains(t, err, "invalid shutdown reason")
+		require.EqualValues(t, 0, requests.Load(), "nothing is sent")
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Never respond, as if coderd is unreachable.
+			<-r.Context().Done()
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err = client.ReportShutdown(ctx, agentsdk.ReportShutdownRequest{Reason: agentsdk.ShutdownReasonTimeout})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, time.Since(start), testutil.WaitShort)
+	})
+}
+
+func TestAgentReportError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		var received agentsdk.ErrorEvent
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v2/workspaceagents/me/report-error", r.URL.Path)
+			if !httpapi.Read(r.Context(), w, r, &received) {
+				return
+			}
+			httpapi.Write(r.Context(), w, http.StatusNoContent, nil)
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		before := time.Now()
+		err = client.ReportError(ctx, agentsdk.ErrorEvent{
+			Code:     "mount_failed",
+			Message:  "mount /home/coder/data: permission denied",
+			Severity: agentsdk.ErrorSeverityWarning,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "mount_failed", received.Code)
+		require.Equal(t, "mount /home/coder/data: permission denied", received.Message)
+		require.Equal(t, agentsdk.ErrorSeverityWarning, received.Severity)
+		require.False(t, received.CreatedAt.Before(before.Truncate(time.Second)), "created at defaults to now")
+
+		createdAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+		err = client.ReportError(ctx, agentsdk.ErrorEvent{
+			Code:      "script_failed",
+			Message:   "exit status 1",
+			Severity:  agentsdk.ErrorSeverityError,
+			CreatedAt: createdAt,
+		})
+		require.NoError(t, err)
+		require.True(t, createdAt.Equal(received.CreatedAt))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int64
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			httpapi.Write(r.Context(), w, http.StatusNoContent, nil)
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err = client.ReportError(ctx, agentsdk.ErrorEvent{Severity: agentsdk.ErrorSeverityError})
+		require.ErrorContains(t, err, "error code must not be empty")
+		err = client.ReportError(ctx, agentsdk.ErrorEvent{Code: "mount_failed", Severity: "fatal"})
+		require.ErrorContains(t, err, "invalid error severity")
+		require.EqualValues(t, 0, requests.Load(), "nothing is sent")
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httpapi.InternalServerError(w, nil)
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		// Failures are returned for the agent to log, not panics.
+		err = client.ReportError(ctx, agentsdk.ErrorEvent{Code: "mount_failed", Severity: agentsdk.ErrorSeverityError})
+		require.Error(t, err)
+	})
+}
+
+func TestAgentClockOffset(t *testing.T) {
+	t.Parallel()
+
+	// The server's clock is an hour ahead.
+	const ahead = time.Hour
+	var (
+		mu     sync.Mutex
+		events []agentsdk.ErrorEvent
+		logs   agentsdk.PatchStartupLogs
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(ahead).UTC().Format(http.TimeFormat))
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v2/workspaceagents/me/report-error":
+			var event agentsdk.ErrorEvent
+			if !httpapi.Read(r.Context(), w, r, &event) {
+				return
+			}
+			events = append(events, event)
+			httpapi.Write(r.Context(), w, http.StatusNoContent, nil)
+		case "/api/v2/workspaceagents/me/startup-logs":
+			if !httpapi.Read(r.Context(), w, r, &logs) {
+				return
+			}
+			httpapi.Write(r.Context(), w, http.StatusOK, nil)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+	client.CorrectTimestamps = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	_, ok := client.ClockOffset()
+	require.False(t, ok, "offset is unknown before a response")
+	createdAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	err = client.ReportError(ctx, agentsdk.ErrorEvent{
+		Code:      "mount_failed",
+		Severity:  agentsdk.ErrorSeverityWarning,
+		CreatedAt: createdAt,
+	})
+	require.NoError(t, err)
+
+	// The Date header has a resolution of a second.
+	offset, ok := client.ClockOffset()
+	require.True(t, ok)
+	require.InDelta(t, ahead, offset, float64(time.Second))
+	require.WithinDuration(t, time.Now().Add(ahead), client.Now(), time.Second)
+
+	err = client.ReportError(ctx, agentsdk.ErrorEvent{
+		Code:      "mount_failed",
+		Severity:  agentsdk.ErrorSeverityWarning,
+		CreatedAt: createdAt,
+	})
+	require.NoError(t, err)
+	original := agentsdk.PatchStartupLogs{Logs: []agentsdk.StartupLog{{
+		CreatedAt: createdAt,
+		Output:    "hello",
+		Level:     codersdk.LogLevelInfo,
+		Source:    agentsdk.StartupLogSourceAgent,
+	}}}
+	err = client.PatchStartupLogs(ctx, original)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 2)
+	require.True(t, createdAt.Equal(events[0].CreatedAt), "the first report is sent before the offset is known")
+	require.WithinDuration(t, createdAt.Add(ahead), events[1].CreatedAt, time.Second)
+	require.Len(t, logs.Logs, 1)
+	require.WithinDuration(t, createdAt.Add(ahead), logs.Logs[0].CreatedAt, time.Second)
+	require.True(t, createdAt.Equal(original.Logs[0].CreatedAt), "the caller's logs are not modified")
+}
+
+func TestAgentCapabilities(t *testing.T) {
+	t.Parallel()
+
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get(agentsdk.CapabilitiesHeader)
+		httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.Metadata{
+			DERPMap: &tailcfg.DERPMap{},
+			// The server acknowledges a capability the agent did not send,
+			// which must be ignored.
+			Capabilities: []agentsdk.Capability{agentsdk.CapabilityGzip, agentsdk.CapabilityWebsocketStats},
+		})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+	client.Capabilities = []agentsdk.Capability{agentsdk.CapabilityGzip, agentsdk.CapabilityH2C}
+	require.False(t, client.HasCapability(agentsdk.CapabilityGzip), "nothing acknowledged yet")
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+	_, err = client.Metadata(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, []agentsdk.Capability{agentsdk.CapabilityGzip, agentsdk.CapabilityH2C}, agentsdk.ParseCapabilities(sent))
+	require.True(t, client.HasCapability(agentsdk.CapabilityGzip))
+	require.False(t, client.HasCapability(agentsdk.CapabilityH2C), "not acknowledged")
+	require.False(t, client.HasCapability(agentsdk.CapabilityWebsocketStats), "not sent")
+}
+
+func TestAgentRequestCompression(t *testing.T) {
+	t.Parallel()
+
+	run := func(t *testing.T, acknowledged []agentsdk.Capability, encoding string) {
+		t.Helper()
+		encodings := make(chan string, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v2/workspaceagents/me/metadata" {
+				httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.Metadata{
+					DERPMap:      &tailcfg.DERPMap{},
+					Capabilities: acknowledged,
+				})
+				return
+			}
+			body, err := agentsdk.DecompressRequest(r)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer body.Close()
+			var stats agentsdk.Stats
+			assert.NoError(t, json.NewDecoder(body).Decode(&stats))
+			assert.Equal(t, int64(7), stats.NumConns)
+			encodings <- r.Header.Get("Content-Encoding")
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.StatsResponse{})
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+		client.Capabilities = []agentsdk.Capability{agentsdk.CapabilityGzip, agentsdk.CapabilityZstd}
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+		_, err = client.Metadata(ctx)
+		require.NoError(t, err)
+		require.Equal(t, encoding, client.RequestEncoding())
+
+		expected := encoding
+		if encoding == agentsdk.EncodingIdentity {
+			expected = ""
+		}
+		// Encoders are reused across requests.
+		for i := 0; i < 2; i++ {
+			_, err = client.PostStats(ctx, &agentsdk.Stats{NumConns: 7})
+			require.NoError(t, err)
+			require.Equal(t, expected, <-encodings)
+		}
+	}
+
+	t.Run("Zstd", func(t *testing.T) {
+		t.Parallel()
+		run(t, []agentsdk.Capability{agentsdk.CapabilityGzip, agentsdk.CapabilityZstd}, agentsdk.EncodingZstd)
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		t.Parallel()
+		run(t, []agentsdk.Capability{agentsdk.CapabilityGzip}, agentsdk.EncodingGzip)
+	})
+
+	t.Run("Identity", func(t *testing.T) {
+		t.Parallel()
+		run(t, nil, agentsdk.EncodingIdentity)
+	})
+}
+
+func TestAgentEnvironmentVariables(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.Metadata{
+				DERPMap: &tailcfg.DERPMap{},
+				EnvironmentVariables: map[string]string{
+					"EDITOR": "vim",
+					"EMPTY":  "",
+				},
+			})
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		env, err := client.EnvironmentVariables(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"EDITOR": "vim", "EMPTY": ""}, env)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("{}"))
+		}))
+		defer srv.Close()
+		parsed, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(parsed)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		env, err := client.EnvironmentVariables(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, env)
+		require.Empty(t, env)
+	})
+}
+
+func TestAgentPatchStartupLogs(t *testing.T) {
+	t.Parallel()
+
+	var received agentsdk.PatchStartupLogs
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !httpapi.Read(r.Context(), w, r, &received) {
+			return
+		}
+		httpapi.Write(r.Context(), w, http.StatusOK, nil)
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	now := time.Now().UTC()
+	logs := []agentsdk.StartupLog{
+		{CreatedAt: now, Output: "starting", Level: codersdk.LogLevelInfo, Source: agentsdk.StartupLogSourceAgent},
+		{CreatedAt: now, Output: "script 1", Level: codersdk.LogLevelDebug, Source: agentsdk.StartupLogSourceStartupScript},
+		{CreatedAt: now, Output: "script 2", Level: codersdk.LogLevelError, Source: agentsdk.StartupLogSourceStartupScript},
+		{CreatedAt: now, Output: "started", Level: codersdk.LogLevelWarn, Source: agentsdk.StartupLogSourceAgent},
+		{CreatedAt: now, Output: "script 3", Level: codersdk.LogLevelInfo, Source: agentsdk.StartupLogSourceStartupScript},
+	}
+	err = client.PatchStartupLogs(ctx, agentsdk.PatchStartupLogs{Logs: logs})
+	require.NoError(t, err)
+	require.Len(t, received.Logs, len(logs))
+	for i, log := range logs {
+		require.Equal(t, log.Output, received.Logs[i].Output)
+		require.Equal(t, log.Level, received.Logs[i].Level)
+		require.Equal(t, log.Source, received.Logs[i].Source)
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		err := client.PatchStartupLogs(ctx, agentsdk.PatchStartupLogs{Logs: []agentsdk.StartupLog{
+			{Output: "bad level", Level: "loud", Source: agentsdk.StartupLogSourceAgent},
+		}})
+		require.ErrorContains(t, err, "invalid log level")
+
+		err = client.PatchStartupLogs(ctx, agentsdk.PatchStartupLogs{Logs: []agentsdk.StartupLog{
+			{Output: "bad source", Level: codersdk.LogLevelInfo, Source: "somewhere"},
+		}})
+		require.ErrorContains(t, err, "invalid log source")
+	})
+}
+
+func TestAgentUploadLog(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		stored   []byte
+		received int
+		patches  int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/workspaceagents/me/log-uploads/startup.log", r.URL.Path)
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet {
+			httpapi.Write(r.Context(), w, http.StatusOK, agentsdk.LogUploadOffset{Offset: int64(len(stored))})
+			return
+		}
+
+		var chunk agentsdk.LogChunk
+		if !httpapi.Read(r.Context(), w, r, &chunk) {
+			return
+		}
+		if chunk.Offset != int64(len(stored)) {
+			httpapi.Write(r.Context(), w, http.StatusConflict, codersdk.Response{
+				Message: "Chunk does not start at the stored offset.",
+			})
+			return
+		}
+		stored = append(stored, chunk.Data...)
+		received += len(chunk.Data)
+		patches++
+		if patches == 3 {
+			// Store the chunk, but close the connection before it is
+			// acknowledged, like an agent restarting mid-upload.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		httpapi.Write(r.Context(), w, http.StatusOK, agentsdk.LogUploadOffset{Offset: int64(len(stored))})
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	log := []byte(strings.Repeat("0123456789", 5) + "end")
+	err = client.UploadLog(ctx, "startup.log", bytes.NewReader(log), 8)
+	require.Error(t, err, "the upload is interrupted")
+
+	// Resuming continues from the last acknowledged byte.
+	err = client.UploadLog(ctx, "startup.log", bytes.NewReader(log), 8)
+	require.NoError(t, err)
+
+	state := func() (string, int, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		return string(stored), received, patches
+	}
+	got, gotReceived, patchesBefore := state()
+	require.Equal(t, string(log), got)
+	require.Equal(t, len(log), gotReceived, "no bytes are sent twice")
+
+	// Uploading a complete log again sends nothing.
+	err = client.UploadLog(ctx, "startup.log", bytes.NewReader(log), 8)
+	require.NoError(t, err)
+	_, _, patchesAfter := state()
+	require.Equal(t, patchesBefore, patchesAfter)
+}
+
+func TestAgentWatchConfig(t *testing.T) {
+	t.Parallel()
+
+	push := make(chan agentsdk.AgentConfig)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/workspaceagents/me/config/watch", r.URL.Path)
+		conn, err := websocket.Accept(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case config := <-push:
+				data, err := json.Marshal(config)
+				if !assert.NoError(t, err) {
+					return
+				}
+				err = conn.Write(r.Context(), websocket.MessageText, data)
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+		}
+	}))
+	defer srv.Close()
+	parsed, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := agentsdk.New(parsed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	configs := make(chan agentsdk.AgentConfig, 1)
+	closer, err := client.WatchConfig(ctx, slogtest.Make(t, nil), func(config agentsdk.AgentConfig) {
+		configs <- config
+	})
+	require.NoError(t, err)
+	defer closer.Close()
+
+	sendConfig := func(config agentsdk.AgentConfig) {
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out pushing config")
+		case push <- config:
+		}
+	}
+	receiveConfig := func() agentsdk.AgentConfig {
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for config")
+		case config := <-configs:
+			return config
+		}
+		return agentsdk.AgentConfig{}
+	}
+
+	sendConfig(agentsdk.AgentConfig{
+		Version:         1,
+		StatsSampleRate: 1,
+	})
+	require.Equal(t, agentsdk.AgentConfig{
+		Version:         1,
+		StatsSampleRate: 1,
+	}, receiveConfig())
+
+	// The current config is not applied again.
+	sendConfig(agentsdk.AgentConfig{Version: 1})
+	sendConfig(agentsdk.AgentConfig{
+		Version:         2,
+		StatsSampleRate: 0.25,
+		Features:        map[string]bool{"port-forwarding": false, "ssh": true},
+	})
+	config := receiveConfig()
+	require.Equal(t, int64(2), config.Version)
+	require.Equal(t, 0.25, config.StatsSampleRate)
+	require.True(t, config.FeatureEnabled("ssh"))
+	require.False(t, config.FeatureEnabled("port-forwarding"))
+	require.False(t, config.FeatureEnabled("unknown"))
+
+	// Versions restart when the control plane restarts.
+	sendConfig(agentsdk.AgentConfig{
+		Version:         1,
+		StatsSampleRate: 0.5,
+	})
+	config = receiveConfig()
+	require.Equal(t, int64(1), config.Version)
+	require.Equal(t, 0.5, config.StatsSampleRate)
+}
@@ -60,8 +60,7 @@ var Keys = map[string]ed25519.PublicKey{"2022-08-12": ed25519.PublicKey(key20220
 // @Router /licenses [post]
 func (api *API) postLicense(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if !api.AGPL.Authorize(r, rbac.ActionCreate, rbac.ResourceLicense) {
-		httpapi.Forbidden(rw)
+	if !api.AGPL.AuthorizeOrForbid(rw, r, rbac.ActionCreate, rbac.ResourceLicense) {
 		return
 	}
 
@@ -182,8 +181,7 @@ func (api *API) licenses(rw http.ResponseWriter, r *http.Request) {
 // @Router /licenses/{id} [delete]
 func (api *API) deleteLicense(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if !api.AGPL.Authorize(r, rbac.ActionDelete, rbac.ResourceLicense) {
-		httpapi.Forbidden(rw)
+	if !api.AGPL.AuthorizeOrForbid(rw, r, rbac.ActionDelete, rbac.ResourceLicense) {
 		return
 	}
 
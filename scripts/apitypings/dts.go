@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EmitStyle is how the generated declarations are wrapped.
+type EmitStyle string
+
+const (
+	// EmitModule emits the declarations as exports of an ES module.
+	EmitModule EmitStyle = "module"
+	// EmitDTS emits the declarations in an ambient module block, for a .d.ts
+	// file.
+	EmitDTS EmitStyle = "dts"
+)
+
+// defaultModuleName is the name of the ambient module when
+// Options.ModuleName is empty.
+const defaultModuleName = "api/typesGenerated"
+
+// constInitializerRegex matches the initializer of const declarations, which
+// are not allowed in ambient contexts.
+var constInitializerRegex = regexp.MustCompile(`(?m)^(\s*export const \w+: [^=\n]+?) = .*$`)
+
+// declareModule wraps the generated output in an ambient module declaration.
+// The consts keep their types, but not their values.
+//
+//	declare module "api/typesGenerated" {
+//	  export const WorkspaceStatuses: WorkspaceStatus[]
+//	}
+func declareModule(output string, moduleName string, indent string) string {
+	output = constInitializerRegex.ReplaceAllString(output, "$1")
+
+	var s strings.Builder
+	// Keep the generated code comment above the module.
+	if strings.HasPrefix(output, prelude) {
+		_, _ = s.WriteString(prelude)
+		output = strings.TrimPrefix(output, prelude)
+	}
+	_, _ = s.WriteString(fmt.Sprintf("declare module %q {\n", moduleName))
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			line = indent + line
+		}
+		_, _ = s.WriteString(line + "\n")
+	}
+	_, _ = s.WriteString("}")
+	return s.String()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// fieldDoc returns the doc comment of a struct field as an indented JSDoc
+// comment, or "" if the field is not documented.
+func (g *Generator) fieldDoc(field *types.Var) string {
+	if g.fieldDocs == nil {
+		g.fieldDocs = make(map[token.Pos]*ast.CommentGroup)
+		for _, file := range g.syntax() {
+			ast.Inspect(file, func(node ast.Node) bool {
+				st, ok := node.(*ast.StructType)
+				if !ok {
+					return true
+				}
+				for _, f := range st.Fields.List {
+					if f.Doc == nil {
+						continue
+					}
+					// Fields declared together, eg "A, B string", share
+					// the doc comment. Embedded fields have no names, and
+					// are positioned at their type.
+					if len(f.Names) == 0 {
+						g.fieldDocs[f.Type.Pos()] = f.Doc
+					}
+					for _, name := range f.Names {
+						g.fieldDocs[name.Pos()] = f.Doc
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	doc, ok := g.fieldDocs[field.Pos()]
+	if !ok {
+		return ""
+	}
+	return g.jsdoc(doc.Text())
+}
+
+// jsdoc formats text as an indented JSDoc comment. A single line is kept on
+// the line of the comment, eg "/** The name. */".
+func (g *Generator) jsdoc(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	// The text can't end the comment early.
+	text = strings.ReplaceAll(text, "*/", "*\\/")
+
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return g.opts.Indent + "/** " + lines[0] + " */"
+	}
+	var doc strings.Builder
+	doc.WriteString(g.opts.Indent + "/**\n")
+	for _, line := range lines {
+		doc.WriteString(strings.TrimRight(g.opts.Indent+" * "+line, " ") + "\n")
+	}
+	doc.WriteString(g.opts.Indent + " */")
+	return doc.String()
+}
@@ -3,7 +3,12 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
 	"os"
 	"path"
@@ -11,12 +16,14 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/fatih/structtag"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/sloghuman"
@@ -25,48 +32,321 @@ import (
 
 const (
 	baseDir = "./codersdk"
-	indent  = "  "
+	// defaultIndent is used when Options.Indent is empty.
+	defaultIndent = "  "
+	// maxTupleLength is the longest array generated as a tuple when
+	// Options.TupleArrays is set.
+	maxTupleLength = 16
 )
 
 func main() {
+	var opts Options
+	flag.BoolVar(&opts.IncludeTests, "include-tests", false, "Also generate types declared in _test.go files")
+	flag.BoolVar(&opts.NoSourceComments, "no-source-comments", false, `Omit the "// From <file>" comment above each type`)
+	flag.BoolVar(&opts.EmitEnumRegistry, "emit-enum-registry", false, "Emit an AnyEnum union of all enum types and an enumNames array")
+	nullableStyle := flag.String("nullable-style", string(NullableOptional), `How fields that may be null are represented: "optional", "comment" or "null"`)
+	additionalPackages := flag.String("additional-packages", "", "Comma separated packages to generate along with ./codersdk, eg ./codersdk/agentsdk")
+	namespacePrefixes := flag.String("namespace-by-prefix", "", "Comma separated type name prefixes, types with a prefix are moved into a namespace named by the prefix")
+	flag.BoolVar(&opts.InterfaceUnions, "interface-unions", false, "Generate interfaces as a union of the types in the package that implement them")
+	flag.BoolVar(&opts.TupleArrays, "tuple-arrays", false, "Generate fixed size arrays as tuples")
+	flag.BoolVar(&opts.ExplicitUndefined, "explicit-undefined", false, `Add "| undefined" to the type of optional fields`)
+	flag.BoolVar(&opts.FieldDocs, "field-docs", false, "Emit the doc comments of struct fields as JSDoc comments")
+	flag.BoolVar(&opts.FlattenEmbeds, "flatten-embeds", false, "Place the fields of embedded structs where they are embedded, instead of extending the embedded interfaces")
+	flag.BoolVar(&opts.EnumSourceOrder, "enum-source-order", false, "Order the arrays of enum values in declaration order instead of alphabetically")
+	flag.BoolVar(&opts.BrandNamedStrings, "brand-named-strings", false, "Generate named string types that are not enums as branded strings")
+	int64Style := flag.String("int64-style", string(Int64Number), `How int64 and uint64, which can exceed javascript's safe integers, are generated: "number", "string" or "brand"`)
+	flag.BoolVar(&opts.NumericRecordKeys, "numeric-record-keys", false, `Generate maps with integer keys as "Record<number, T>" instead of "Record<string, T>"`)
+	flag.BoolVar(&opts.JSONNumberUnion, "json-number-union", false, `Generate json.Number as "number | string" instead of "number"`)
+	flag.StringVar(&opts.ByteArrayEncoding, "byte-array-encoding", "", `Encoding of fixed size byte arrays such as "hex", documented in a comment above byte array fields`)
+	flag.StringVar(&opts.Since, "since", "", "Previously generated file, types are only regenerated if a Go file they depend on changed since")
+	configFile := flag.String("config", "", "YAML config file with type overrides")
+	emitStyle := flag.String("emit-style", string(EmitModule), `How the output is wrapped: "module" for ES module exports, or "dts" for an ambient module in a .d.ts file`)
+	flag.StringVar(&opts.ModuleName, "module-name", defaultModuleName, `Name of the ambient module with -emit-style "dts"`)
+	flag.StringVar(&opts.Indent, "indent", defaultIndent, `Indentation used for generated fields. Escape sequences such as "\t" are supported`)
+	var outputPath string
+	flag.StringVar(&outputPath, "output", "", "File to write the generated types to, instead of stdout")
+	flag.StringVar(&outputPath, "o", "", "Shorthand for -output")
+	check := flag.Bool("check", false, "Exit with an error if the -output file is not up to date, instead of writing it")
+	flag.Parse()
+
 	ctx := context.Background()
 	log := slog.Make(sloghuman.Sink(os.Stderr))
-	output, err := Generate(baseDir)
+	indent, err := strconv.Unquote(`"` + opts.Indent + `"`)
+	if err != nil {
+		log.Fatal(ctx, "invalid indent", slog.F("indent", opts.Indent), slog.Error(err))
+	}
+	opts.Indent = indent
+
+	if *configFile != "" {
+		config, err := loadConfig(*configFile)
+		if err != nil {
+			log.Fatal(ctx, "load config", slog.Error(err))
+		}
+		opts.TypeOverrides = config.Overrides
+	}
+
+	if *additionalPackages != "" {
+		opts.AdditionalPackages = strings.Split(*additionalPackages, ",")
+	}
+
+	if *namespacePrefixes != "" {
+		opts.NamespacePrefixes = strings.Split(*namespacePrefixes, ",")
+	}
+
+	opts.NullableStyle = NullableStyle(*nullableStyle)
+	switch opts.NullableStyle {
+	case NullableOptional, NullableComment, NullableUnion:
+	default:
+		log.Fatal(ctx, "invalid nullable style", slog.F("nullable_style", opts.NullableStyle))
+	}
+
+	opts.Int64Style = Int64Style(*int64Style)
+	switch opts.Int64Style {
+	case Int64Number, Int64String, Int64Brand:
+	default:
+		log.Fatal(ctx, "invalid int64 style", slog.F("int64_style", opts.Int64Style))
+	}
+
+	opts.EmitStyle = EmitStyle(*emitStyle)
+	switch opts.EmitStyle {
+	case EmitModule, EmitDTS:
+	default:
+		log.Fatal(ctx, "invalid emit style", slog.F("emit_style", opts.EmitStyle))
+	}
+
+	if *check && outputPath == "" {
+		log.Fatal(ctx, "-check requires -output")
+	}
+
+	output, err := Generate(baseDir, opts)
 	if err != nil {
 		log.Fatal(ctx, err.Error())
 	}
 
-	// Just cat the output to a file to capture it
-	fmt.Println(output)
+	if outputPath == "" {
+		// Just cat the output to a file to capture it
+		fmt.Println(output)
+		return
+	}
+	// Match the trailing newline of stdout.
+	output += "\n"
+	if *check {
+		err = checkOutput(outputPath, output)
+	} else {
+		err = writeOutput(outputPath, output)
+	}
+	if err != nil {
+		log.Fatal(ctx, err.Error())
+	}
+}
+
+// Options configures optional generator behavior. The zero value generates
+// the same output as 'make site/src/api/typesGenerated.ts'.
+type Options struct {
+	// IncludeTests also generates the types declared in the package's
+	// _test.go files.
+	IncludeTests bool
+	// AdditionalPackages are generated along with the package, eg
+	// "./codersdk/agentsdk". Types can reference types in any of the
+	// packages, so names must be unique across them.
+	AdditionalPackages []string
+	// Indent is the string used to indent generated fields and comments.
+	// Defaults to two spaces.
+	Indent string
+	// NoSourceComments omits the "// From <file>" comment above each type.
+	NoSourceComments bool
+	// EmitEnumRegistry emits a union of all enum types named AnyEnum, and an
+	// enumNames array listing them.
+	EmitEnumRegistry bool
+	// NullableStyle is how optional fields are represented. Defaults to
+	// NullableOptional.
+	NullableStyle NullableStyle
+	// InterfaceUnions generates interfaces with methods as a union of the
+	// types in the package that implement them. Otherwise they are unknown.
+	InterfaceUnions bool
+	// TupleArrays generates fixed size arrays as tuples, eg [3]int is
+	// [number, number, number]. Arrays longer than maxTupleLength are still
+	// generated as arrays.
+	TupleArrays bool
+	// ExplicitUndefined adds "| undefined" to the type of optional fields,
+	// for typescript's exactOptionalPropertyTypes.
+	ExplicitUndefined bool
+	// FieldDocs emits the doc comments of struct fields as JSDoc comments
+	// above the generated fields.
+	FieldDocs bool
+	// FlattenEmbeds places the fields of embedded structs where they are
+	// embedded, instead of extending the embedded interfaces.
+	FlattenEmbeds bool
+	// EnumSourceOrder orders the array of enum values in the order the
+	// constants are declared, instead of alphabetically.
+	EnumSourceOrder bool
+	// BrandNamedStrings generates named string types without constants as
+	// branded strings, eg `type Username string` is
+	// `string & { __brand: "Username" }`. Enums are not affected.
+	BrandNamedStrings bool
+	// Int64Style is how 64 bit integers are generated. Defaults to
+	// Int64Number.
+	Int64Style Int64Style
+	// NumericRecordKeys generates maps with integer keys as
+	// Record<number, T>. Otherwise they are Record<string, T>, as JSON
+	// object keys are always strings.
+	NumericRecordKeys bool
+	// JSONNumberUnion generates json.Number as `number | string`, as it is
+	// unmarshaled from both. Otherwise it is a number, which is how it is
+	// marshaled.
+	JSONNumberUnion bool
+	// ByteArrayEncoding is the encoding of fixed size byte arrays, such as
+	// "hex". When set, byte array and byte slice fields have a comment
+	// describing their encoding.
+	ByteArrayEncoding string
+	// TypeOverrides replaces the generated type of Go types, keyed by the
+	// qualified Go type name, eg "github.com/coder/coder/codersdk.Date".
+	TypeOverrides map[string]TypeOverride
+	// NamespacePrefixes moves types that start with a prefix into a
+	// namespace named by the prefix, eg WorkspaceBuild -> Workspace.Build.
+	NamespacePrefixes []string
+	// EmitStyle is how the declarations are wrapped. Defaults to EmitModule.
+	EmitStyle EmitStyle
+	// ModuleName is the name of the ambient module with EmitDTS. Defaults
+	// to "api/typesGenerated".
+	ModuleName string
+	// PostProcess is applied to the generated output by Generate, eg to
+	// prepend a license header or run a formatter.
+	PostProcess func(output string) (string, error)
+	// Since is a previously generated file. Its code blocks are cached next
+	// to it, and reused when no Go file they depend on has changed since.
+	Since string
+}
+
+// TypeOverride replaces the generated type of a Go type.
+type TypeOverride struct {
+	// Type is the typescript type.
+	Type string `yaml:"type"`
+	// Comment is placed above fields using the type, eg to describe the
+	// format of a string.
+	Comment string `yaml:"comment"`
+}
+
+// Config is the format of the file passed with -config.
+//
+//	overrides:
+//	  github.com/coder/coder/codersdk.Date:
+//	    type: string
+//	    comment: Formatted as YYYY-MM-DD
+type Config struct {
+	Overrides map[string]TypeOverride `yaml:"overrides"`
+}
+
+// loadConfig reads a config file.
+func loadConfig(name string) (Config, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return Config{}, xerrors.Errorf("read config: %w", err)
+	}
+	var config Config
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return Config{}, xerrors.Errorf("parse config %q: %w", name, err)
+	}
+	for typeName, override := range config.Overrides {
+		if override.Type == "" {
+			return Config{}, xerrors.Errorf("override %q must have a type", typeName)
+		}
+	}
+	return config, nil
 }
 
-func Generate(directory string) (string, error) {
+// NullableStyle is how fields that may be null or absent on the wire are
+// represented.
+type NullableStyle string
+
+const (
+	// NullableOptional marks fields that may be null or absent as optional.
+	NullableOptional NullableStyle = "optional"
+	// NullableComment is NullableOptional with a comment above each
+	// optional field, saying if it is null or absent when unset.
+	NullableComment NullableStyle = "comment"
+	// NullableUnion adds "| null" to the type of fields that may be null,
+	// and marks fields that may be absent as optional.
+	NullableUnion NullableStyle = "null"
+)
+
+// Int64Style is how int64 and uint64 are generated. Javascript numbers are
+// only exact up to 2^53, so larger values lose precision when decoded.
+type Int64Style string
+
+const (
+	// Int64Number generates 64 bit integers as a number, like other
+	// integers.
+	Int64Number Int64Style = "number"
+	// Int64String generates 64 bit integers as a string with a comment,
+	// for APIs that encode them with `json:",string"`.
+	Int64String Int64Style = "string"
+	// Int64Brand generates 64 bit integers as the Int64 branded number, so
+	// they are not mixed up with numbers that are always safe.
+	Int64Brand Int64Style = "brand"
+)
+
+// int64Brand is the declaration of the branded type used by Int64Brand.
+const int64Brand = `// Int64 is a Go int64 or uint64, which is not exact beyond
+// Number.MAX_SAFE_INTEGER.
+export type Int64 = number & { __brand: "Int64" }
+`
+
+func Generate(directory string, opts Options) (string, error) {
 	ctx := context.Background()
 	log := slog.Make(sloghuman.Sink(os.Stderr))
-	codeBlocks, err := GenerateFromDirectory(ctx, log, directory)
+	codeBlocks, err := GenerateFromDirectory(ctx, log, directory, opts)
 	if err != nil {
 		return "", err
 	}
 
+	output := codeBlocks.String()
+	if opts.EmitStyle == EmitDTS {
+		moduleName := opts.ModuleName
+		if moduleName == "" {
+			moduleName = defaultModuleName
+		}
+		indent := opts.Indent
+		if indent == "" {
+			indent = defaultIndent
+		}
+		output = declareModule(output, moduleName, indent)
+	}
+	if opts.PostProcess != nil {
+		output, err = opts.PostProcess(output)
+		if err != nil {
+			return "", xerrors.Errorf("post process: %w", err)
+		}
+	}
+
 	// Just cat the output to a file to capture it
-	return codeBlocks.String(), nil
+	return output, nil
 }
 
+// prelude is written at the top of the generated output.
+const prelude = `
+// Code generated by 'make site/src/api/typesGenerated.ts'. DO NOT EDIT.
+
+`
+
 // TypescriptTypes holds all the code blocks created.
 type TypescriptTypes struct {
 	// Each entry is the type name, and it's typescript code block.
 	Types    map[string]string
 	Enums    map[string]string
 	Generics map[string]string
+	// EnumRegistry is a union of all enum types. Only set when
+	// Options.EmitEnumRegistry is true.
+	EnumRegistry string
+	// Namespaces are code blocks of types grouped by prefix, keyed by the
+	// namespace name. Only set when Options.NamespacePrefixes is set.
+	Namespaces map[string]string
 }
 
 // String just combines all the codeblocks.
 func (t TypescriptTypes) String() string {
 	var s strings.Builder
-	const prelude = `
-// Code generated by 'make site/src/api/typesGenerated.ts'. DO NOT EDIT.
-
-`
 	_, _ = s.WriteString(prelude)
 
 	sortedTypes := make([]string, 0, len(t.Types))
@@ -99,38 +379,86 @@ func (t TypescriptTypes) String() string {
 		_, _ = s.WriteRune('\n')
 	}
 
+	if t.EnumRegistry != "" {
+		_, _ = s.WriteString(t.EnumRegistry)
+		_, _ = s.WriteRune('\n')
+	}
+
 	for _, k := range sortedGenerics {
 		v := t.Generics[k]
 		_, _ = s.WriteString(v)
 		_, _ = s.WriteRune('\n')
 	}
 
+	sortedNamespaces := make([]string, 0, len(t.Namespaces))
+	for k := range t.Namespaces {
+		sortedNamespaces = append(sortedNamespaces, k)
+	}
+	sort.Strings(sortedNamespaces)
+	for _, k := range sortedNamespaces {
+		_, _ = s.WriteString(t.Namespaces[k])
+		_, _ = s.WriteRune('\n')
+	}
+
 	return strings.TrimRight(s.String(), "\n")
 }
 
 // GenerateFromDirectory will return all the typescript code blocks for a directory
-func GenerateFromDirectory(ctx context.Context, log slog.Logger, directory string) (*TypescriptTypes, error) {
+func GenerateFromDirectory(ctx context.Context, log slog.Logger, directory string, opts Options) (*TypescriptTypes, error) {
+	if opts.Indent == "" {
+		opts.Indent = defaultIndent
+	}
 	g := Generator{
 		log:      log,
+		opts:     opts,
 		builtins: make(map[string]string),
 	}
-	err := g.parsePackage(ctx, directory)
+	if opts.Since != "" {
+		since, err := newSinceState(directory, opts)
+		if err != nil {
+			return nil, xerrors.Errorf("since %q: %w", opts.Since, err)
+		}
+		if since.unchanged() {
+			// Loading the packages is the slow part, and nothing the code
+			// blocks depend on changed.
+			codeBlocks := since.previous.codeBlocks(opts)
+			if len(opts.NamespacePrefixes) > 0 {
+				namespaceByPrefix(codeBlocks, opts.NamespacePrefixes, opts.Indent)
+			}
+			return codeBlocks, nil
+		}
+		g.since = since
+	}
+	err := g.parsePackage(ctx, append([]string{directory}, opts.AdditionalPackages...)...)
 	if err != nil {
 		return nil, xerrors.Errorf("parse package %q: %w", directory, err)
 	}
+	if g.since != nil {
+		g.since.markDirty(g.mentions())
+	}
 
 	codeBlocks, err := g.generateAll()
 	if err != nil {
 		return nil, xerrors.Errorf("parse package %q: %w", directory, err)
 	}
+	err = g.writeSinceCache(codeBlocks)
+	if err != nil {
+		return nil, xerrors.Errorf("write since cache: %w", err)
+	}
+
+	if len(opts.NamespacePrefixes) > 0 {
+		namespaceByPrefix(codeBlocks, opts.NamespacePrefixes, opts.Indent)
+	}
 
 	return codeBlocks, nil
 }
 
 type Generator struct {
-	// Package we are scanning.
-	pkg *packages.Package
-	log slog.Logger
+	// Packages we are scanning. Types are generated from all of them, and
+	// can reference each other.
+	pkgs []*packages.Package
+	log  slog.Logger
+	opts Options
 
 	// builtins is kinda a hack to get around the fact that using builtin
 	// generic constraints is common. We want to support them even though
@@ -140,6 +468,11 @@ type Generator struct {
 	// cannot be implemented in go. So they are a first class thing that we just
 	// have to make a static string for ¯\_(ツ)_/¯
 	builtins map[string]string
+	// fieldDocs are the doc comments of struct fields by position, built
+	// on first use by fieldDoc.
+	fieldDocs map[token.Pos]*ast.CommentGroup
+	// since is set with Options.Since, to reuse unchanged code blocks.
+	since *sinceState
 }
 
 // parsePackage takes a list of patterns such as a directory, and parses them.
@@ -148,8 +481,8 @@ func (g *Generator) parsePackage(ctx context.Context, patterns ...string) error
 		// Just accept the fact we need these flags for what we want. Feel free to add
 		// more, it'll just increase the time it takes to parse.
 		Mode: packages.NeedTypes | packages.NeedName | packages.NeedTypesInfo |
-			packages.NeedTypesSizes | packages.NeedSyntax,
-		Tests:   false,
+			packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedFiles | packages.NeedModule,
+		Tests:   g.opts.IncludeTests,
 		Context: ctx,
 	}
 
@@ -157,30 +490,119 @@ func (g *Generator) parsePackage(ctx context.Context, patterns ...string) error
 	if err != nil {
 		return xerrors.Errorf("load package: %w", err)
 	}
+	if g.opts.IncludeTests {
+		pkgs = testVariants(pkgs)
+	}
 
-	// Only support 1 package for now. We can expand it if we need later, we
-	// just need to hook up multiple packages in the generator.
-	if len(pkgs) != 1 {
-		return xerrors.Errorf("expected 1 package, found %d", len(pkgs))
+	if len(pkgs) == 0 {
+		return xerrors.New("no packages found")
 	}
 
-	g.pkg = pkgs[0]
+	g.pkgs = pkgs
 	return nil
 }
 
+// loaded returns the loaded package of a types package, or nil if the
+// package is not generated.
+func (g *Generator) loaded(pkg *types.Package) *packages.Package {
+	if pkg == nil {
+		return nil
+	}
+	for _, p := range g.pkgs {
+		if p.Types == pkg {
+			return p
+		}
+	}
+	return nil
+}
+
+// lookup returns the object with the name in any of the packages, in the
+// order the packages were loaded.
+func (g *Generator) lookup(name string) types.Object {
+	for _, pkg := range g.pkgs {
+		if obj := pkg.Types.Scope().Lookup(name); obj != nil {
+			return obj
+		}
+	}
+	return nil
+}
+
+// qualifier formats types from generated packages without their package
+// name, as they are generated without one.
+func (g *Generator) qualifier(pkg *types.Package) string {
+	if g.loaded(pkg) != nil {
+		return ""
+	}
+	return pkg.Name()
+}
+
+// constsOf returns the constants of the named type. Only constants declared
+// in the package of a generated type are found.
+func (g *Generator) constsOf(named *types.Named) []*types.Const {
+	pkg := g.loaded(named.Obj().Pkg())
+	if pkg == nil {
+		return nil
+	}
+	var consts []*types.Const
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if ok && types.Identical(c.Type(), named) {
+			consts = append(consts, c)
+		}
+	}
+	return consts
+}
+
+// testVariants reduces the packages loaded with tests enabled to a single
+// package per import path. Loading with tests returns the package itself, the
+// package recompiled with its _test.go files, the external "_test" package and
+// the generated test binary. Only the recompiled variant is kept, as it is a
+// superset of the package and keeps the types from being generated twice.
+func testVariants(pkgs []*packages.Package) []*packages.Package {
+	hasVariant := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.ID, "]") && !strings.HasSuffix(pkg.Name, "_test") {
+			hasVariant[pkg.PkgPath] = true
+		}
+	}
+
+	filtered := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		switch {
+		case strings.HasSuffix(pkg.PkgPath, ".test"):
+			// The generated test main package.
+			continue
+		case strings.HasSuffix(pkg.Name, "_test"):
+			// External test packages cannot be referenced by the package
+			// under test.
+			continue
+		case hasVariant[pkg.PkgPath] && !strings.HasSuffix(pkg.ID, "]"):
+			// Prefer the variant that includes the test files.
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	return filtered
+}
+
 // generateAll will generate for all types found in the pkg
 func (g *Generator) generateAll() (*TypescriptTypes, error) {
 	m := &Maps{
-		Structs:      make(map[string]string),
-		Generics:     make(map[string]string),
-		Enums:        make(map[string]types.Object),
-		EnumConsts:   make(map[string][]*types.Const),
-		IgnoredTypes: make(map[string]struct{}),
+		Structs:        make(map[string]string),
+		Generics:       make(map[string]string),
+		Enums:          make(map[string]types.Object),
+		EnumConsts:     make(map[string][]*types.Const),
+		IgnoredTypes:   make(map[string]struct{}),
+		EnumValues:     make(map[string][]string),
+		RawTypes:       make(map[string]string),
+		RealEnums:      make(map[string]struct{}),
+		Discriminators: make(map[string]string),
 	}
 
 	// Look for comments that indicate to ignore a type for typescript generation.
 	ignoreRegex := regexp.MustCompile("@typescript-ignore[:]?(?P<ignored_types>.*)")
-	for _, file := range g.pkg.Syntax {
+	for _, file := range g.syntax() {
 		for _, comment := range file.Comments {
 			for _, line := range comment.List {
 				text := line.Text
@@ -196,16 +618,157 @@ func (g *Generator) generateAll() (*TypescriptTypes, error) {
 		}
 	}
 
-	for _, n := range g.pkg.Types.Scope().Names() {
-		obj := g.pkg.Types.Scope().Lookup(n)
-		err := g.generateOne(m, obj)
-		if err != nil {
-			return nil, xerrors.Errorf("%q: %w", n, err)
+	// Enum types can list their wire values explicitly. This is useful when
+	// the constants are not the values sent over the wire, eg integer
+	// constants that marshal to strings.
+	//	@typescript-enum-values:"a","b"
+	// Any type can be replaced by a typescript type, eg for types with a
+	// custom MarshalJSON.
+	//	@typescript-raw:"Record<string, number>"
+	// Enum types can be a typescript enum instead of a union of values.
+	//	@typescript-enum
+	// Union interfaces of structs can be discriminated by a field, which
+	// every member must have as a string literal.
+	//	@typescript-discriminator:type
+	enumValuesRegex := regexp.MustCompile(`@typescript-enum-values:(.*)`)
+	rawRegex := regexp.MustCompile(`@typescript-raw:(.*)`)
+	realEnumRegex := regexp.MustCompile(`@typescript-enum\s*$`)
+	discriminatorRegex := regexp.MustCompile(`@typescript-discriminator:(\w+)`)
+	for _, file := range g.syntax() {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				doc := typeSpec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if doc == nil {
+					continue
+				}
+				for _, line := range doc.List {
+					if matches := rawRegex.FindStringSubmatch(line.Text); matches != nil {
+						raw, err := strconv.Unquote(strings.TrimSpace(matches[1]))
+						if err != nil {
+							return nil, xerrors.Errorf("%q: raw type %s must be a quoted string: %w", typeSpec.Name.Name, matches[1], err)
+						}
+						m.RawTypes[typeSpec.Name.Name] = raw
+						continue
+					}
+					if realEnumRegex.MatchString(line.Text) {
+						m.RealEnums[typeSpec.Name.Name] = struct{}{}
+						continue
+					}
+					if matches := discriminatorRegex.FindStringSubmatch(line.Text); matches != nil {
+						m.Discriminators[typeSpec.Name.Name] = matches[1]
+						continue
+					}
+					matches := enumValuesRegex.FindStringSubmatch(line.Text)
+					if len(matches) != 2 {
+						continue
+					}
+					values, err := parseEnumValues(matches[1])
+					if err != nil {
+						return nil, xerrors.Errorf("%q: parse enum values: %w", typeSpec.Name.Name, err)
+					}
+					m.EnumValues[typeSpec.Name.Name] = values
+				}
+			}
+		}
+	}
+
+	// Untyped string constants can be grouped into an enum with a directive
+	// above the const block.
+	//	// @typescript-enum-group:Name
+	//	const (
+	//		A = "a"
+	//		B = "b"
+	//	)
+	enumGroupRegex := regexp.MustCompile(`@typescript-enum-group:(\w+)`)
+	for _, pkg := range g.pkgs {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST || gen.Doc == nil {
+					continue
+				}
+				var group string
+				for _, line := range gen.Doc.List {
+					if matches := enumGroupRegex.FindStringSubmatch(line.Text); matches != nil {
+						group = matches[1]
+					}
+				}
+				if group == "" {
+					continue
+				}
+				if _, ok := m.IgnoredTypes[group]; ok {
+					continue
+				}
+				if g.lookup(group) != nil {
+					return nil, xerrors.Errorf("enum group %q conflicts with a declaration of the same name", group)
+				}
+				err := g.enumGroup(m, pkg.Types, group, gen)
+				if err != nil {
+					return nil, xerrors.Errorf("enum group %q: %w", group, err)
+				}
+			}
+		}
+	}
+
+	// Types are generated without their package, so names must be unique
+	// across packages.
+	declared := make(map[string]string)
+	for _, pkg := range g.pkgs {
+		for _, n := range pkg.Types.Scope().Names() {
+			if _, ok := pkg.Types.Scope().Lookup(n).(*types.TypeName); !ok {
+				continue
+			}
+			if _, ok := m.IgnoredTypes[n]; ok {
+				continue
+			}
+			if other, ok := declared[n]; ok {
+				return nil, xerrors.Errorf("type %q is declared in both %q and %q", n, other, pkg.PkgPath)
+			}
+			declared[n] = pkg.PkgPath
+		}
+	}
+
+	for _, pkg := range g.pkgs {
+		for _, n := range pkg.Types.Scope().Names() {
+			obj := pkg.Types.Scope().Lookup(n)
+			if _, ok := obj.(*types.TypeName); ok {
+				// Enums are still registered, as their code blocks are
+				// built after all constants are found.
+				if block, ok := g.reuse(n); ok && block.Kind != blockEnum {
+					switch block.Kind {
+					case blockGeneric:
+						m.Generics[n] = block.Code
+					default:
+						m.Structs[n] = block.Code
+					}
+					continue
+				}
+			}
+			g.since.setBuilding(n)
+			err := g.generateOne(m, obj)
+			g.since.setBuilding("")
+			if err != nil {
+				return nil, xerrors.Errorf("%q: %w", n, err)
+			}
 		}
 	}
 
 	// Add the builtins
 	for n, value := range g.builtins {
+		if _, ok := m.Structs[n]; ok {
+			return nil, xerrors.Errorf("type %q conflicts with a generated builtin type", n)
+		}
 		if value != "" {
 			m.Generics[n] = value
 		}
@@ -214,13 +777,47 @@ func (g *Generator) generateAll() (*TypescriptTypes, error) {
 	// Write all enums
 	enumCodeBlocks := make(map[string]string)
 	for name, v := range m.Enums {
+		if block, ok := g.reuse(name); ok && block.Kind == blockEnum {
+			enumCodeBlocks[name] = block.Code
+			continue
+		}
+		consts := m.EnumConsts[name]
+		sort.Slice(consts, func(i, j int) bool {
+			return consts[i].Pos() < consts[j].Pos()
+		})
 		var values []string
-		for _, elem := range m.EnumConsts[name] {
-			// TODO: If we have non string constants, we need to handle that
-			//		here.
-			values = append(values, elem.Val().String())
+		for _, elem := range consts {
+			// Strings are quoted, numbers are not.
+			values = append(values, constantLiteral(elem.Val()))
+		}
+		// Numeric enums are sorted by value, unless their values are
+		// listed as strings.
+		numeric := isNumeric(v.Type())
+		if override, ok := m.EnumValues[name]; ok {
+			values = override
+			numeric = false
+		}
+		if _, ok := m.RealEnums[name]; ok {
+			if _, ok := m.EnumValues[name]; ok {
+				return nil, xerrors.Errorf("enum %q: @typescript-enum members are named after the constants and cannot use @typescript-enum-values", name)
+			}
+			enumCodeBlocks[name] = g.buildRealEnum(v, name, consts)
+			continue
+		}
+		if len(values) == 0 && g.opts.BrandNamedStrings && isString(v.Type()) {
+			// Named strings without constants are not enums, but are
+			// still distinct from other strings.
+			m.Structs[name] = g.posLine(v) + fmt.Sprintf("export type %s = string & { __brand: %q }\n", name, name)
+			continue
+		}
+		// Values are in declaration order, or the order of
+		// @typescript-enum-values.
+		ordered := values
+		values = append([]string{}, values...)
+		sortEnumValues(values, numeric)
+		if !g.opts.EnumSourceOrder {
+			ordered = values
 		}
-		sort.Strings(values)
 		var s strings.Builder
 		_, _ = s.WriteString(g.posLine(v))
 		joined := strings.Join(values, " | ")
@@ -232,34 +829,305 @@ func (g *Generator) generateAll() (*TypescriptTypes, error) {
 			name, joined,
 		))
 
-		var pluralName string
-		if strings.HasSuffix(name, "s") {
-			pluralName = name + "es"
-		} else {
-			pluralName = name + "s"
-		}
-
 		// Generate array used for enumerating all possible values.
 		_, _ = s.WriteString(fmt.Sprintf("export const %s: %s[] = [%s]\n",
-			pluralName, name, strings.Join(values, ", "),
+			enumPluralName(name), name, strings.Join(ordered, ", "),
 		))
 
 		enumCodeBlocks[name] = s.String()
 	}
 
+	var registry string
+	if g.opts.EmitEnumRegistry {
+		registry = buildEnumRegistry(enumCodeBlocks)
+	}
+
 	return &TypescriptTypes{
-		Types:    m.Structs,
-		Enums:    enumCodeBlocks,
-		Generics: m.Generics,
+		Types:        m.Structs,
+		Enums:        enumCodeBlocks,
+		Generics:     m.Generics,
+		EnumRegistry: registry,
 	}, nil
 }
 
+// buildRealEnum returns a typescript enum with a member for each constant.
+// Members are named after the constant without the enum name prefix, eg
+// the member of BuildReasonInitiator is Initiator.
+func (g *Generator) buildRealEnum(obj types.Object, name string, consts []*types.Const) string {
+	type member struct {
+		key   string
+		value string
+	}
+	members := make([]member, 0, len(consts))
+	for _, c := range consts {
+		key := strings.TrimPrefix(c.Name(), name)
+		if key == "" || !token.IsIdentifier(key) {
+			key = c.Name()
+		}
+		members = append(members, member{key: key, value: constantLiteral(c.Val())})
+	}
+	// Like the values of unions, members are sorted unless they follow the
+	// declaration order of the constants.
+	if !g.opts.EnumSourceOrder {
+		numeric := isNumeric(obj.Type())
+		sort.SliceStable(members, func(i, j int) bool {
+			return enumValueLess(members[i].value, members[j].value, numeric)
+		})
+	}
+
+	var s strings.Builder
+	_, _ = s.WriteString(g.posLine(obj))
+	_, _ = s.WriteString(fmt.Sprintf("export enum %s {\n", name))
+	refs := make([]string, 0, len(members))
+	for _, m := range members {
+		_, _ = s.WriteString(fmt.Sprintf("%s%s = %s,\n", g.opts.Indent, m.key, m.value))
+		refs = append(refs, name+"."+m.key)
+	}
+	_, _ = s.WriteString("}\n")
+
+	// Generate array used for enumerating all possible values, like
+	// unions.
+	_, _ = s.WriteString(fmt.Sprintf("export const %s: %s[] = [%s]\n",
+		enumPluralName(name), name, strings.Join(refs, ", "),
+	))
+	return s.String()
+}
+
+// quotedType returns the type of a field with the `json:",string"` option,
+// which encodes numbers and booleans as strings. Enums are a union of their
+// quoted values, eg `"1" | "2"`. Other types are unchanged.
+func (g *Generator) quotedType(ty types.Type, ts TypescriptType) TypescriptType {
+	basic, ok := ty.Underlying().(*types.Basic)
+	if !ok || basic.Info()&(types.IsNumeric|types.IsBoolean) == 0 {
+		return ts
+	}
+
+	var values []string
+	if named, ok := ty.(*types.Named); ok {
+		for _, c := range g.constsOf(named) {
+			values = append(values, tsString(constantLiteral(c.Val())))
+		}
+	}
+	if len(values) == 0 {
+		return TypescriptType{ValueType: "string", Optional: ts.Optional}
+	}
+	sort.Strings(values)
+	return TypescriptType{ValueType: strings.Join(values, " | "), Optional: ts.Optional}
+}
+
+// implementers returns the names of the types in the packages that
+// implement the interface, or whose pointer implements it. Interfaces,
+// generic and ignored types are excluded.
+func (g *Generator) implementers(intf *types.Interface, ignored map[string]struct{}) []string {
+	var names []string
+	for _, pkg := range g.pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !obj.Exported() {
+				continue
+			}
+			if _, ok := ignored[name]; ok {
+				continue
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+			if _, ok := named.Underlying().(*types.Interface); ok {
+				continue
+			}
+			if types.Implements(named, intf) || types.Implements(types.NewPointer(named), intf) {
+				names = append(names, name)
+			}
+		}
+	}
+	// Sort the names, so the union is stable.
+	sort.Strings(names)
+	return names
+}
+
+// hasMarshalJSON returns true if the type or a pointer to it has a custom
+// MarshalJSON method.
+func hasMarshalJSON(named *types.Named) bool {
+	obj, _, _ := types.LookupFieldOrMethod(named, true, named.Obj().Pkg(), "MarshalJSON")
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// isNumeric returns true if the underlying type is an integer or float.
+func isNumeric(ty types.Type) bool {
+	basic, ok := ty.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsNumeric != 0
+}
+
+// sortEnumValues sorts the values of an enum, numerically if they are
+// numbers.
+func sortEnumValues(values []string, numeric bool) {
+	sort.SliceStable(values, func(i, j int) bool {
+		return enumValueLess(values[i], values[j], numeric)
+	})
+}
+
+// enumValueLess compares the typescript literals of two enum values.
+func enumValueLess(a, b string, numeric bool) bool {
+	if numeric {
+		// Literals of numeric constants always parse.
+		x, errX := strconv.ParseFloat(a, 64)
+		y, errY := strconv.ParseFloat(b, 64)
+		if errX == nil && errY == nil {
+			return x < y
+		}
+	}
+	return a < b
+}
+
+// parseTypescriptTag returns the `typescript` struct tag. Commas inside brackets
+// are part of the type, eg `typescript:"Record<string, string>"`, not
+// separators of the options.
+func parseTypescriptTag(tags *structtag.Tags) (*structtag.Tag, error) {
+	tag, err := tags.Get("typescript")
+	if err != nil {
+		return nil, err
+	}
+	parts := append([]string{tag.Name}, tag.Options...)
+	name := parts[0]
+	i := 1
+	for ; i < len(parts) && bracketDepth(name) > 0; i++ {
+		name += "," + parts[i]
+	}
+	return &structtag.Tag{
+		Key:     tag.Key,
+		Name:    name,
+		Options: parts[i:],
+	}, nil
+}
+
+// bracketDepth returns the number of unclosed brackets in the string.
+func bracketDepth(s string) int {
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '<', '(', '[', '{':
+			depth++
+		case '>', ')', ']', '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// isDuration returns true if the type is a time.Duration, or a pointer to
+// one.
+func isDuration(ty types.Type) bool {
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = ptr.Elem()
+	}
+	named, ok := ty.(*types.Named)
+	return ok && named.String() == "time.Duration"
+}
+
+// isString returns true if the underlying type is a string.
+func isString(ty types.Type) bool {
+	basic, ok := ty.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// enumPluralName is the name of the array holding all values of an enum.
+func enumPluralName(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// buildEnumRegistry returns a union of all enum types, and an array of
+// their names.
+func buildEnumRegistry(enums map[string]string) string {
+	names := make([]string, 0, len(enums))
+	for name := range enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	union := strings.Join(names, " | ")
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+	if union == "" {
+		union = "never"
+	}
+
+	var s strings.Builder
+	_, _ = s.WriteString("// AnyEnum is a union of all enum types.\n")
+	_, _ = s.WriteString(fmt.Sprintf("export type AnyEnum = %s\n", union))
+	_, _ = s.WriteString(fmt.Sprintf("export const enumNames: string[] = [%s]\n", strings.Join(quoted, ", ")))
+	return s.String()
+}
+
+// enumGroup adds the untyped string constants of the const block as the
+// values of an enum.
+func (g *Generator) enumGroup(m *Maps, pkg *types.Package, group string, decl *ast.GenDecl) error {
+	for _, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for _, name := range valueSpec.Names {
+			c, ok := pkg.Scope().Lookup(name.Name).(*types.Const)
+			if !ok {
+				// Blank identifiers are not declared.
+				continue
+			}
+			if _, ok := c.Type().(*types.Named); ok {
+				return xerrors.Errorf("constant %q has named type %q, only untyped constants can be grouped", c.Name(), c.Type().String())
+			}
+			if c.Val().Kind() != constant.String {
+				return xerrors.Errorf("constant %q is not a string", c.Name())
+			}
+			if _, ok := m.Enums[group]; !ok {
+				m.Enums[group] = c
+			}
+			m.EnumConsts[group] = append(m.EnumConsts[group], c)
+		}
+	}
+	return nil
+}
+
 type Maps struct {
 	Structs      map[string]string
 	Generics     map[string]string
 	Enums        map[string]types.Object
 	EnumConsts   map[string][]*types.Const
 	IgnoredTypes map[string]struct{}
+	// EnumValues are enum values listed with @typescript-enum-values. They
+	// take precedence over the values of the enum constants.
+	EnumValues map[string][]string
+	// RawTypes are typescript types listed with @typescript-raw. They
+	// replace the generated type.
+	RawTypes map[string]string
+	// RealEnums are enum types marked with @typescript-enum. They are
+	// generated as typescript enums instead of unions.
+	RealEnums map[string]struct{}
+	// Discriminators are the field names of unions marked with
+	// @typescript-discriminator.
+	Discriminators map[string]string
+}
+
+// parseEnumValues parses a comma separated list of quoted strings.
+// The values are returned quoted, in the same form as string constants.
+func parseEnumValues(list string) ([]string, error) {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, xerrors.Errorf("value %s must be a quoted string: %w", value, err)
+		}
+		values = append(values, tsString(unquoted))
+	}
+	return values, nil
 }
 
 func (g *Generator) generateOne(m *Maps, obj types.Object) error {
@@ -276,18 +1144,36 @@ func (g *Generator) generateOne(m *Maps, obj types.Object) error {
 	switch obj := obj.(type) {
 	// All named types are type declarations
 	case *types.TypeName:
+		if _, ok := g.opts.TypeOverrides[obj.Type().String()]; ok {
+			// Overridden types are replaced where they are used, so the
+			// declaration is not needed.
+			return nil
+		}
 		named, ok := obj.Type().(*types.Named)
 		if !ok {
 			panic("all typename should be named types")
 		}
+		if raw, ok := m.RawTypes[obj.Name()]; ok {
+			m.Structs[obj.Name()] = g.posLine(obj) + fmt.Sprintf("export type %s = %s\n", obj.Name(), raw)
+			return nil
+		}
 		switch underNamed := named.Underlying().(type) {
 		case *types.Struct:
 			// type <Name> struct
 			// Structs are obvious.
-			codeBlock, err := g.buildStruct(obj, underNamed)
+			codeBlock, err := g.buildStruct(obj, underNamed, m.IgnoredTypes)
 			if err != nil {
 				return xerrors.Errorf("generate %q: %w", obj.Name(), err)
 			}
+			if hasMarshalJSON(named) {
+				// The fields might not be what is sent over the wire.
+				g.log.Warn(context.Background(), "type implements json.Marshaler, use @typescript-raw if the generated type does not match",
+					slog.F("type", obj.Name()),
+				)
+				pos := g.posLine(obj)
+				codeBlock = pos + fmt.Sprintf("// %s implements json.Marshaler, this type might not match the JSON.\n", obj.Name()) +
+					strings.TrimPrefix(codeBlock, pos)
+			}
 			m.Structs[obj.Name()] = codeBlock
 		case *types.Basic:
 			// type <Name> string
@@ -314,26 +1200,26 @@ func (g *Generator) generateOne(m *Maps, obj types.Object) error {
 			str.WriteString(fmt.Sprintf("export type %s = %s\n", obj.Name(), ts.ValueType))
 			m.Structs[obj.Name()] = str.String()
 		case *types.Interface:
-			// Interfaces are used as generics. Non-generic interfaces are
-			// not supported.
-			if underNamed.NumEmbeddeds() == 1 {
-				union, ok := underNamed.EmbeddedType(0).(*types.Union)
-				if !ok {
-					// If the underlying is not a union, but has 1 type. It's
-					// just that one type.
-					union = types.NewUnion([]*types.Term{
-						// Set the tilde to true to support underlying.
-						// Doesn't actually affect our generation.
-						types.NewTerm(true, underNamed.EmbeddedType(0)),
-					})
-				}
-
-				block, err := g.buildUnion(obj, union)
+			// Interfaces with a union are used as generics.
+			if terms, ok := constraintUnion(underNamed); ok {
+				block, err := g.buildUnion(obj, terms, m.Discriminators[obj.Name()])
 				if err != nil {
 					return xerrors.Errorf("generate union %q: %w", obj.Name(), err)
 				}
 				m.Generics[obj.Name()] = block
+				break
+			}
+			if underNamed.Empty() || !underNamed.IsMethodSet() {
+				break
+			}
+			// Fields can hold any type implementing the interface.
+			valueType := "unknown"
+			if g.opts.InterfaceUnions {
+				if implementers := g.implementers(underNamed, m.IgnoredTypes); len(implementers) > 0 {
+					valueType = strings.Join(implementers, " | ")
+				}
 			}
+			m.Structs[obj.Name()] = g.posLine(obj) + fmt.Sprintf("export type %s = %s\n", obj.Name(), valueType)
 		case *types.Signature:
 		// Ignore named functions.
 		default:
@@ -345,51 +1231,242 @@ func (g *Generator) generateOne(m *Maps, obj types.Object) error {
 	case *types.Var:
 		// TODO: Are any enums var declarations? This is also codersdk.Me.
 	case *types.Const:
-		// We only care about named constant types, since they are enums
-		if named, ok := obj.Type().(*types.Named); ok {
+		// We only care about named constant types, since they are enums.
+		// Constants of types from other packages are not their values.
+		if named, ok := obj.Type().(*types.Named); ok && named.Obj().Pkg() == obj.Pkg() {
 			name := named.Obj().Name()
 			m.EnumConsts[name] = append(m.EnumConsts[name], obj)
 		}
-	case *types.Func:
-		// Noop
-	default:
-		fmt.Println(obj.Name())
+	case *types.Func:
+		// Noop
+	default:
+		fmt.Println(obj.Name())
+	}
+	return nil
+}
+
+func (g *Generator) posLine(obj types.Object) string {
+	if g.opts.NoSourceComments {
+		return ""
+	}
+	file := g.pkgs[0].Fset.File(obj.Pos())
+	// Do not use filepath, as that changes behavior based on OS
+	return fmt.Sprintf("// From %s\n", path.Join(g.sourceDir(obj.Pkg()), filepath.Base(file.Name())))
+}
+
+// sourceDir is the directory of a package in the "// From" comments. The
+// first package is "codersdk", and packages nested in it are relative to
+// it, eg "codersdk/agentsdk". Other packages are relative to the parent of
+// the first package.
+func (g *Generator) sourceDir(pkg *types.Package) string {
+	root := g.pkgs[0].PkgPath
+	if pkg == nil || pkg.Path() == root {
+		return "codersdk"
+	}
+	if strings.HasPrefix(pkg.Path(), root+"/") {
+		return path.Join("codersdk", strings.TrimPrefix(pkg.Path(), root+"/"))
+	}
+	return strings.TrimPrefix(pkg.Path(), path.Dir(root)+"/")
+}
+
+// syntax returns the files of all packages.
+func (g *Generator) syntax() []*ast.File {
+	var files []*ast.File
+	for _, pkg := range g.pkgs {
+		files = append(files, pkg.Syntax...)
+	}
+	return files
+}
+
+// lookupNamed returns the declaration of a named type that other generated
+// types can reference by name. Types from the generated packages are looked
+// up in their own package. Types from other packages are referenced if a
+// generated package declares a type with the same name.
+func (g *Generator) lookupNamed(n *types.Named) types.Object {
+	if pkg := g.loaded(n.Obj().Pkg()); pkg != nil {
+		return pkg.Types.Scope().Lookup(n.Obj().Name())
+	}
+	return g.lookup(n.Obj().Name())
+}
+
+// buildStruct just prints the typescript def for a type.
+func (g *Generator) buildUnion(obj types.Object, terms []*types.Term, discriminator string) (string, error) {
+	var s strings.Builder
+	_, _ = s.WriteString(g.posLine(obj))
+	if discriminator != "" {
+		// Every member must have a literal discriminator for typescript to
+		// narrow the union.
+		for _, term := range terms {
+			_, err := g.discriminatorLiteral(term.Type(), discriminator)
+			if err != nil {
+				return "", xerrors.Errorf("union %q member %q: %w", obj.Name(), term.Type().String(), err)
+			}
+		}
+		_, _ = s.WriteString(fmt.Sprintf("// %s is discriminated by its %q field.\n", obj.Name(), discriminator))
+	}
+
+	allTypes, optional, err := g.unionTypes(terms)
+	if err != nil {
+		return "", xerrors.Errorf("union for %q failed to get type: %w", obj.Name(), err)
+	}
+
+	if optional {
+		allTypes = append(allTypes, "null")
+	}
+
+	allTypes = slice.Unique(allTypes)
+	if len(allTypes) == 0 {
+		// The embedded interfaces have no types in common.
+		allTypes = []string{"never"}
+	}
+
+	s.WriteString(fmt.Sprintf("export type %s = %s\n", obj.Name(), strings.Join(allTypes, " | ")))
+
+	return s.String(), nil
+}
+
+// discriminatorLiteral returns the string literal type of the field with the
+// json name key on the struct type ty. The field must be a string literal
+// with a `typescript:"\"literal\""` tag, or an enum with a single value.
+func (g *Generator) discriminatorLiteral(ty types.Type, key string) (string, error) {
+	st, ok := ty.Underlying().(*types.Struct)
+	if !ok {
+		return "", xerrors.Errorf("must be a struct to be discriminated")
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tags, err := structtag.Parse(st.Tag(i))
+		if err != nil {
+			return "", xerrors.Errorf("invalid struct tags on field %q: %w", field.Name(), err)
+		}
+		name := field.Name()
+		if jsonTag, err := tags.Get("json"); err == nil && jsonTag.Name != "" {
+			name = jsonTag.Name
+		}
+		if name != key {
+			continue
+		}
+
+		if typescriptTag, err := parseTypescriptTag(tags); err == nil && typescriptTag.Name != "" {
+			if _, err := strconv.Unquote(typescriptTag.Name); err != nil || !strings.HasPrefix(typescriptTag.Name, `"`) {
+				return "", xerrors.Errorf("discriminator %q must be a string literal, found %s", key, typescriptTag.Name)
+			}
+			return typescriptTag.Name, nil
+		}
+		// Enums with a single value are literals.
+		if named, ok := field.Type().(*types.Named); ok && isString(named) {
+			if consts := g.constsOf(named); len(consts) == 1 {
+				return constantLiteral(consts[0].Val()), nil
+			}
+		}
+		return "", xerrors.Errorf("discriminator %q must be a string literal or an enum with a single value, found %q", key, field.Type().String())
 	}
-	return nil
-}
-
-func (g *Generator) posLine(obj types.Object) string {
-	file := g.pkg.Fset.File(obj.Pos())
-	// Do not use filepath, as that changes behavior based on OS
-	return fmt.Sprintf("// From %s\n", path.Join("codersdk", filepath.Base(file.Name())))
+	return "", xerrors.Errorf("missing discriminator field %q", key)
 }
 
-// buildStruct just prints the typescript def for a type.
-func (g *Generator) buildUnion(obj types.Object, st *types.Union) (string, error) {
-	var s strings.Builder
-	_, _ = s.WriteString(g.posLine(obj))
-
-	allTypes := make([]string, 0, st.Len())
+// unionTypes returns the typescript type of each term in the union. If any
+// of the terms are optional, the union is optional.
+func (g *Generator) unionTypes(terms []*types.Term) ([]string, bool, error) {
+	allTypes := make([]string, 0, len(terms))
 	var optional bool
-	for i := 0; i < st.Len(); i++ {
-		term := st.Term(i)
+	for _, term := range terms {
 		scriptType, err := g.typescriptType(term.Type())
 		if err != nil {
-			return "", xerrors.Errorf("union %q for %q failed to get type: %w", st.String(), obj.Name(), err)
+			return nil, false, err
 		}
 		allTypes = append(allTypes, scriptType.ValueType)
 		optional = optional || scriptType.Optional
 	}
+	return allTypes, optional, nil
+}
 
-	if optional {
-		allTypes = append(allTypes, "null")
+// constraintUnion returns the terms of the union of types an interface is
+// constrained to.
+// Constraint interfaces in the union are flattened into their types, so the
+// union does not reference constraints that may not be generated, eg from
+// other packages. An interface embedding several elements is constrained to
+// the types in all of them.
+func constraintUnion(intf *types.Interface) ([]*types.Term, bool) {
+	terms, ok := constraintTerms(intf)
+	if !ok {
+		if intf.NumEmbeddeds() != 1 {
+			return nil, false
+		}
+		// If the embedded type has no types of its own, eg comparable, it
+		// is referenced as the only type.
+		// Set the tilde to true to support underlying.
+		// Doesn't actually affect our generation.
+		terms = []*types.Term{types.NewTerm(true, intf.EmbeddedType(0))}
 	}
+	return terms, true
+}
 
-	allTypes = slice.Unique(allTypes)
+// constraintTerms returns the terms of the types an interface is
+// constrained to, or false if it is not constrained to any types, eg
+// interfaces with only methods.
+func constraintTerms(intf *types.Interface) ([]*types.Term, bool) {
+	var (
+		terms      []*types.Term
+		restricted bool
+	)
+	for i := 0; i < intf.NumEmbeddeds(); i++ {
+		elemTerms, ok := elementTerms(intf.EmbeddedType(i))
+		if !ok {
+			// Embedded interfaces without types, eg comparable, don't
+			// restrict the types.
+			continue
+		}
+		if !restricted {
+			terms = elemTerms
+			restricted = true
+			continue
+		}
+		terms = intersectTerms(terms, elemTerms)
+	}
+	return terms, restricted
+}
 
-	s.WriteString(fmt.Sprintf("export type %s = %s\n", obj.Name(), strings.Join(allTypes, " | ")))
+// elementTerms returns the terms of an element embedded in an interface.
+func elementTerms(elem types.Type) ([]*types.Term, bool) {
+	switch elem := elem.(type) {
+	case *types.Union:
+		terms := make([]*types.Term, 0, elem.Len())
+		for i := 0; i < elem.Len(); i++ {
+			term := elem.Term(i)
+			if intf, ok := term.Type().Underlying().(*types.Interface); ok {
+				if inner, ok := constraintTerms(intf); ok {
+					terms = append(terms, inner...)
+					continue
+				}
+			}
+			terms = append(terms, term)
+		}
+		return terms, true
+	default:
+		if intf, ok := elem.Underlying().(*types.Interface); ok {
+			return constraintTerms(intf)
+		}
+		return []*types.Term{types.NewTerm(false, elem)}, true
+	}
+}
 
-	return s.String(), nil
+// intersectTerms returns the terms of the types in both a and b.
+func intersectTerms(a, b []*types.Term) []*types.Term {
+	var terms []*types.Term
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case types.Identical(x.Type(), y.Type()):
+				terms = append(terms, types.NewTerm(x.Tilde() && y.Tilde(), x.Type()))
+			case x.Tilde() && types.Identical(x.Type(), y.Type().Underlying()):
+				// ~int includes every type with an underlying int.
+				terms = append(terms, y)
+			case y.Tilde() && types.Identical(y.Type(), x.Type().Underlying()):
+				terms = append(terms, x)
+			}
+		}
+	}
+	return terms
 }
 
 type structTemplateState struct {
@@ -409,7 +1486,7 @@ const structTemplate = `{{ .PosLine -}}
 `
 
 // buildStruct just prints the typescript def for a type.
-func (g *Generator) buildStruct(obj types.Object, st *types.Struct) (string, error) {
+func (g *Generator) buildStruct(obj types.Object, st *types.Struct, ignored map[string]struct{}) (string, error) {
 	state := structTemplateState{}
 	tpl := template.New("struct")
 	tpl.Funcs(template.FuncMap{
@@ -423,27 +1500,217 @@ func (g *Generator) buildStruct(obj types.Object, st *types.Struct) (string, err
 	state.PosLine = g.posLine(obj)
 	state.Name = obj.Name()
 
-	// Handle named embedded structs in the codersdk package via extension.
-	var extends []string
-	extendedFields := make(map[int]bool)
+	genericsUsed := make(map[string]string)
+	if g.opts.FlattenEmbeds {
+		state.Fields, err = g.flattenedFields(obj, st, genericsUsed)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		extends, inlined, err := g.embeddedStructs(obj, st, ignored, genericsUsed)
+		if err != nil {
+			return "", err
+		}
+		if len(extends) > 0 {
+			state.Extends = strings.Join(extends, ", ")
+		}
+		state.Fields = inlined
+
+		fields, err := g.structFields(obj, st, embeddedFields(st), genericsUsed)
+		if err != nil {
+			return "", err
+		}
+		state.Fields = append(state.Fields, fields...)
+	}
+
+	// This is implemented to ensure the correct order of generics on the
+	// top level structure. Ordering of generic fields is important, and
+	// we want to match the same order as Golang. The gathering of generic types
+	// from our fields does not guarantee the order.
+	named, ok := obj.(*types.TypeName)
+	if !ok {
+		return "", xerrors.Errorf("generic param ordering undefined on %q", obj.Name())
+	}
+
+	namedType, ok := named.Type().(*types.Named)
+	if !ok {
+		return "", xerrors.Errorf("generic param %q unexpected type %q", obj.Name(), named.Type().String())
+	}
+
+	// Ensure proper generic param ordering
+	params := namedType.TypeParams()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		name := param.String()
+
+		constraint, ok := genericsUsed[param.String()]
+		if !ok {
+			// If this error is thrown, it is because you have defined a
+			// generic field on a structure, but did not use it in your
+			// fields. If this happens, remove the unused generic on
+			// the top level structure. We **technically** can implement
+			// this still, but it's not a case we need to support.
+			// Example:
+			//	type Foo[A any] struct {
+			//	  Bar string
+			//	}
+			return "", xerrors.Errorf("generic param %q missing on %q, fix your data structure", name, obj.Name())
+		}
+
+		state.Generics = append(state.Generics, fmt.Sprintf("%s extends %s", name, constraint))
+	}
+
+	data := bytes.NewBuffer(make([]byte, 0))
+	err = tpl.Execute(data, state)
+	if err != nil {
+		return "", xerrors.Errorf("execute struct template: %w", err)
+	}
+	return data.String(), nil
+}
+
+// embeddedFields returns the indexes of the embedded structs, which the json
+// package flattens into the struct. These are not generated as fields, see
+// embeddedStructs.
+func embeddedFields(st *types.Struct) map[int]bool {
+	embedded := make(map[int]bool)
 	for i := 0; i < st.NumFields(); i++ {
 		field := st.Field(i)
 		tag := reflect.StructTag(st.Tag(i))
 		// Adding a json struct tag causes the json package to consider
 		// the field unembedded.
-		if field.Embedded() && tag.Get("json") == "" && field.Pkg().Name() == "codersdk" {
-			extendedFields[i] = true
-			extends = append(extends, field.Name())
+		if !field.Embedded() || tag.Get("json") != "" {
+			continue
+		}
+		fieldType := field.Type()
+		if ptr, ok := fieldType.(*types.Pointer); ok {
+			fieldType = ptr.Elem()
+		}
+		if named, ok := fieldType.(*types.Named); ok && named.TypeArgs().Len() > 0 {
+			// Extending instantiated generics is not supported, so they
+			// are generated as a field.
+			continue
+		}
+		if _, ok := inlineStruct(field.Type()); ok {
+			embedded[i] = true
 		}
 	}
-	if len(extends) > 0 {
-		state.Extends = strings.Join(extends, ", ")
+	return embedded
+}
+
+// generated returns true if the named type is generated, so other types can
+// reference it by name.
+func (g *Generator) generated(named *types.Named, ignored map[string]struct{}) bool {
+	obj := named.Obj()
+	if g.loaded(obj.Pkg()) == nil || obj.Pkg().Scope().Lookup(obj.Name()) != obj {
+		return false
 	}
+	_, ok := ignored[obj.Name()]
+	return !ok
+}
 
-	genericsUsed := make(map[string]string)
+// embeddedStructs handles embedded structs that are generated via extension.
+// Embedded structs that are not generated, such as ignored types or types
+// from other packages, have their fields inlined instead, and the structs
+// they embed are extended. This keeps every field reachable through chains
+// of embedded structs. The inlined fields of an embedded pointer are not
+// marked optional.
+func (g *Generator) embeddedStructs(obj types.Object, st *types.Struct, ignored map[string]struct{}, genericsUsed map[string]string) (extends []string, inlined []string, err error) {
+	embedded := embeddedFields(st)
+	for i := 0; i < st.NumFields(); i++ {
+		if !embedded[i] {
+			continue
+		}
+		field := st.Field(i)
+		fieldType := field.Type()
+		ptr, isPointer := fieldType.(*types.Pointer)
+		if isPointer {
+			fieldType = ptr.Elem()
+		}
+		named, ok := fieldType.(*types.Named)
+		if !ok || !g.generated(named, ignored) {
+			inner, _ := inlineStruct(field.Type())
+			embeddedExtends, embeddedInlined, err := g.embeddedStructs(obj, inner, ignored, genericsUsed)
+			if err != nil {
+				return nil, nil, xerrors.Errorf("embedded field %q: %w", field.Name(), err)
+			}
+			fields, err := g.structFields(obj, inner, embeddedFields(inner), genericsUsed)
+			if err != nil {
+				return nil, nil, xerrors.Errorf("embedded field %q: %w", field.Name(), err)
+			}
+			extends = append(extends, embeddedExtends...)
+			inlined = append(inlined, embeddedInlined...)
+			inlined = append(inlined, fields...)
+			continue
+		}
+		if isPointer {
+			// Embedded pointers may be nil, in which case none of
+			// their fields are present.
+			extends = append(extends, fmt.Sprintf("Partial<%s>", field.Name()))
+			continue
+		}
+		extends = append(extends, field.Name())
+	}
+	return extends, inlined, nil
+}
+
+// flattenedFields returns the fields of the struct with the fields of
+// embedded structs in place of the embedded struct, recursively. Like
+// inlined fields, the fields of an embedded pointer are not marked optional.
+func (g *Generator) flattenedFields(obj types.Object, st *types.Struct, genericsUsed map[string]string) ([]string, error) {
+	embedded := embeddedFields(st)
+	var fields []string
+	// Fields between embedded structs are generated together, by skipping
+	// the fields outside of [from, to).
+	run := func(from, to int) error {
+		if from == to {
+			return nil
+		}
+		skip := make(map[int]bool, st.NumFields())
+		for i := 0; i < st.NumFields(); i++ {
+			skip[i] = i < from || i >= to
+		}
+		runFields, err := g.structFields(obj, st, skip, genericsUsed)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, runFields...)
+		return nil
+	}
+
+	from := 0
+	for i := 0; i < st.NumFields(); i++ {
+		if !embedded[i] {
+			continue
+		}
+		err := run(from, i)
+		if err != nil {
+			return nil, err
+		}
+		from = i + 1
+
+		field := st.Field(i)
+		inner, _ := inlineStruct(field.Type())
+		innerFields, err := g.flattenedFields(obj, inner, genericsUsed)
+		if err != nil {
+			return nil, xerrors.Errorf("embedded field %q: %w", field.Name(), err)
+		}
+		fields = append(fields, innerFields...)
+	}
+	err := run(from, st.NumFields())
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// structFields returns a typescript field line for each json field in the
+// struct. Fields in skip are omitted. Generics used by the fields are added
+// to genericsUsed.
+func (g *Generator) structFields(obj types.Object, st *types.Struct, skip map[int]bool, genericsUsed map[string]string) ([]string, error) {
+	var fields []string
 	// For each field in the struct, we print 1 line of the typescript interface
 	for i := 0; i < st.NumFields(); i++ {
-		if extendedFields[i] {
+		if skip[i] {
 			continue
 		}
 		field := st.Field(i)
@@ -452,12 +1719,14 @@ func (g *Generator) buildStruct(obj types.Object, st *types.Struct) (string, err
 		if err != nil {
 			panic("invalid struct tags on type " + obj.String())
 		}
+		typescriptTag, typescriptTagErr := parseTypescriptTag(tags)
 
 		// Use the json name if present
 		jsonTag, err := tags.Get("json")
 		var (
 			jsonName     string
 			jsonOptional bool
+			jsonString   bool
 		)
 		if err == nil {
 			if jsonTag.Name == "-" {
@@ -468,22 +1737,73 @@ func (g *Generator) buildStruct(obj types.Object, st *types.Struct) (string, err
 			if len(jsonTag.Options) > 0 && jsonTag.Options[0] == "omitempty" {
 				jsonOptional = true
 			}
+			jsonString = jsonTag.HasOption("string")
 		}
 		if jsonName == "" {
 			jsonName = field.Name()
 		}
 
+		// If you specify `typescript:",inline"` then the fields of the
+		// referenced struct are placed in this interface instead of being
+		// nested under the field name.
+		if typescriptTagErr == nil && typescriptTag.HasOption("inline") {
+			inline, ok := inlineStruct(field.Type())
+			if !ok {
+				return nil, xerrors.Errorf("inline field %q on %q must be a struct, found %q", field.Name(), obj.Name(), field.Type().String())
+			}
+			inlineFields, err := g.structFields(obj, inline, nil, genericsUsed)
+			if err != nil {
+				return nil, xerrors.Errorf("inline field %q: %w", field.Name(), err)
+			}
+			fields = append(fields, inlineFields...)
+			continue
+		}
+
 		// Infer the type.
-		tsType, err := g.typescriptType(field.Type())
+		var tsType TypescriptType
+		if typescriptTagErr == nil && typescriptTag.HasOption("opaque") {
+			// If you specify `typescript:",opaque"` then the field is not
+			// traversed, eg for caches or other containers that are not
+			// meant to be read by the frontend.
+			tsType = TypescriptType{
+				ValueType:     "unknown",
+				AboveTypeLine: g.indentedComment(fmt.Sprintf("Opaque %s, the contents are not typed.", types.TypeString(field.Type(), g.qualifier))),
+			}
+		} else if typescriptTagErr == nil && typescriptTag.HasOption("tuples") {
+			// If you specify `typescript:",tuples"` on a map, then the map is
+			// an array of key value pairs.
+			m, ok := field.Type().Underlying().(*types.Map)
+			if !ok {
+				return nil, xerrors.Errorf("tuples field %q on %q must be a map, found %q", field.Name(), obj.Name(), field.Type().String())
+			}
+			tsType, err = g.tupleMapType(m)
+		} else {
+			tsType, err = g.typescriptType(field.Type())
+		}
 		if err != nil {
-			return "", xerrors.Errorf("typescript type: %w", err)
+			return nil, xerrors.Errorf("typescript type: %w", err)
+		}
+
+		// If you specify `json:",string"` then numbers and booleans are
+		// encoded as strings.
+		if jsonString {
+			tsType = g.quotedType(field.Type(), tsType)
+		}
+
+		// If you specify `typescript:",milliseconds"` on a duration, then
+		// the duration is sent in milliseconds, eg by a custom MarshalJSON.
+		if typescriptTagErr == nil && typescriptTag.HasOption("milliseconds") {
+			if !isDuration(field.Type()) {
+				return nil, xerrors.Errorf("milliseconds field %q on %q must be a time.Duration, found %q", field.Name(), obj.Name(), field.Type().String())
+			}
+			tsType.AboveTypeLine = g.indentedComment("time.Duration, in milliseconds")
 		}
 
 		// If a `typescript:"string"` exists, we take this, and ignore what we
 		// inferred.
-		typescriptTag, err := tags.Get("typescript")
-		if err == nil {
-			if err == nil && typescriptTag.Name == "-" {
+		var forceOptional bool
+		if typescriptTagErr == nil {
+			if typescriptTag.Name == "-" {
 				// Completely ignore this field.
 				continue
 			} else if typescriptTag.Name != "" {
@@ -497,10 +1817,17 @@ func (g *Generator) buildStruct(obj types.Object, st *types.Struct) (string, err
 			if len(typescriptTag.Options) > 0 && typescriptTag.Options[0] == "notnull" {
 				tsType.Optional = false
 			}
+			// If you specify `typescript:",optional"` then the field is
+			// optional regardless of its Go type, for fields the API may
+			// leave out even though they are not pointers or omitempty.
+			forceOptional = typescriptTag.HasOption("optional")
 		}
 
+		// Fields that are omitted when empty are absent on the wire, while
+		// pointers without omitempty are null.
+		nullable := tsType.Optional && !jsonOptional
 		optional := ""
-		if jsonOptional || tsType.Optional {
+		if jsonOptional || forceOptional || (nullable && g.opts.NullableStyle != NullableUnion) {
 			optional = "?"
 		}
 		valueType := tsType.ValueType
@@ -525,56 +1852,132 @@ func (g *Generator) buildStruct(obj types.Object, st *types.Struct) (string, err
 			}
 		}
 
+		if nullable && g.opts.NullableStyle == NullableUnion {
+			valueType += " | null"
+		}
+		if optional != "" && g.opts.ExplicitUndefined {
+			// With exactOptionalPropertyTypes, undefined can only be
+			// assigned to optional fields that include it.
+			valueType += " | undefined"
+		}
+
+		if g.opts.FieldDocs {
+			if doc := g.fieldDoc(field); doc != "" {
+				fields = append(fields, doc)
+			}
+		}
 		if tsType.AboveTypeLine != "" {
 			// Just append these as fields. We should fix this later.
-			state.Fields = append(state.Fields, tsType.AboveTypeLine)
+			fields = append(fields, tsType.AboveTypeLine)
+		}
+		if g.opts.NullableStyle == NullableComment {
+			switch {
+			case jsonOptional:
+				fields = append(fields, g.indentedComment("Omitted when empty, never null."))
+			case nullable:
+				fields = append(fields, g.indentedComment("Null when unset, never omitted."))
+			}
 		}
-		state.Fields = append(state.Fields, fmt.Sprintf("%sreadonly %s%s: %s", indent, jsonName, optional, valueType))
+		fields = append(fields, fmt.Sprintf("%sreadonly %s%s: %s", g.opts.Indent, jsonName, optional, valueType))
 	}
+	return fields, nil
+}
 
-	// This is implemented to ensure the correct order of generics on the
-	// top level structure. Ordering of generic fields is important, and
-	// we want to match the same order as Golang. The gathering of generic types
-	// from our fields does not guarantee the order.
-	named, ok := obj.(*types.TypeName)
-	if !ok {
-		return "", xerrors.Errorf("generic param ordering undefined on %q", obj.Name())
+// constantLiteral returns the typescript literal of a constant. Floats are
+// formatted the way encoding/json marshals them, the shortest representation
+// that parses to the same float64. constant.Value.String rounds floats to 6
+// significant digits, and shortens long strings.
+func constantLiteral(val constant.Value) string {
+	switch val.Kind() {
+	case constant.String:
+		return tsString(constant.StringVal(val))
+	case constant.Float:
+		f, _ := constant.Float64Val(val)
+		// Constants cannot be NaN or infinite, so this cannot fail.
+		data, _ := json.Marshal(f)
+		return string(data)
+	default:
+		return val.String()
 	}
+}
 
-	namedType, ok := named.Type().(*types.Named)
-	if !ok {
-		return "", xerrors.Errorf("generic param %q unexpected type %q", obj.Name(), named.Type().String())
+// tsString returns s as a typescript string literal. Go quoting is not used,
+// as escapes such as \a and \x00 are not valid in typescript.
+func tsString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Strings always encode.
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// isCompound returns true if the typescript type is a union or intersection,
+// which must be wrapped in parentheses to be used as an array element.
+func isCompound(ts string) bool {
+	depth := 0
+	for _, r := range ts {
+		switch r {
+		case '<', '(', '[', '{':
+			depth++
+		case '>', ')', ']', '}':
+			depth--
+		case '|', '&':
+			if depth == 0 {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	// Ensure proper generic param ordering
-	params := namedType.TypeParams()
-	for i := 0; i < params.Len(); i++ {
-		param := params.At(i)
-		name := param.String()
+// inlineStruct returns the struct referenced by a field with the
+// `typescript:",inline"` option.
+func inlineStruct(ty types.Type) (*types.Struct, bool) {
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = ptr.Elem()
+	}
+	st, ok := ty.Underlying().(*types.Struct)
+	return st, ok
+}
 
-		constraint, ok := genericsUsed[param.String()]
-		if !ok {
-			// If this error is thrown, it is because you have defined a
-			// generic field on a structure, but did not use it in your
-			// fields. If this happens, remove the unused generic on
-			// the top level structure. We **technically** can implement
-			// this still, but it's not a case we need to support.
-			// Example:
-			//	type Foo[A any] struct {
-			//	  Bar string
-			//	}
-			return "", xerrors.Errorf("generic param %q missing on %q, fix your data structure", name, obj.Name())
-		}
+// mapTypes returns the typescript types of the map key and value.
+func (g *Generator) mapTypes(m *types.Map) (keyType TypescriptType, valueType TypescriptType, aboveTypeLine string, err error) {
+	keyType, err = g.typescriptType(m.Key())
+	if err != nil {
+		return TypescriptType{}, TypescriptType{}, "", xerrors.Errorf("map key: %w", err)
+	}
+	valueType, err = g.typescriptType(m.Elem())
+	if err != nil {
+		return TypescriptType{}, TypescriptType{}, "", xerrors.Errorf("map key: %w", err)
+	}
 
-		state.Generics = append(state.Generics, fmt.Sprintf("%s extends %s", name, constraint))
+	aboveTypeLine = keyType.AboveTypeLine
+	if aboveTypeLine != "" && valueType.AboveTypeLine != "" {
+		aboveTypeLine = aboveTypeLine + "\n"
 	}
+	aboveTypeLine = aboveTypeLine + valueType.AboveTypeLine
+	return keyType, valueType, aboveTypeLine, nil
+}
 
-	data := bytes.NewBuffer(make([]byte, 0))
-	err = tpl.Execute(data, state)
+// tupleMapType returns the map as an array of key value pairs. This is
+// used for maps that marshal to pairs, such as maps with struct keys.
+//
+//	map[Key]string -> Array<[Key, string]>
+func (g *Generator) tupleMapType(m *types.Map) (TypescriptType, error) {
+	keyType, valueType, aboveTypeLine, err := g.mapTypes(m)
 	if err != nil {
-		return "", xerrors.Errorf("execute struct template: %w", err)
+		return TypescriptType{}, err
 	}
-	return data.String(), nil
+	value := valueType.ValueType
+	if valueType.Optional && g.opts.NullableStyle == NullableUnion {
+		// Pointer values are marshaled as null, like in records.
+		value += " | null"
+	}
+	return TypescriptType{
+		ValueType:     fmt.Sprintf("Array<[%s, %s]>", keyType.ValueType, value),
+		AboveTypeLine: aboveTypeLine,
+	}, nil
 }
 
 type TypescriptType struct {
@@ -621,13 +2024,23 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		bs := ty
 		// All basic literals (string, bool, int, etc).
 		switch {
+		case bs.Kind() == types.Int64 || bs.Kind() == types.Uint64:
+			switch g.opts.Int64Style {
+			case Int64String:
+				return TypescriptType{ValueType: "string", AboveTypeLine: g.indentedComment(bs.Name() + ", encoded as a string")}, nil
+			case Int64Brand:
+				g.builtins["Int64"] = int64Brand
+				g.since.builtin("Int64")
+				return TypescriptType{ValueType: "Int64"}, nil
+			}
+			return TypescriptType{ValueType: "number"}, nil
 		case bs.Info()&types.IsNumeric > 0:
 			return TypescriptType{ValueType: "number"}, nil
 		case bs.Info()&types.IsBoolean > 0:
 			return TypescriptType{ValueType: "boolean"}, nil
 		case bs.Kind() == types.Byte:
 			// TODO: @emyrk What is a byte for typescript? A string? A uint8?
-			return TypescriptType{ValueType: "number", AboveTypeLine: indentedComment("This is a byte in golang")}, nil
+			return TypescriptType{ValueType: "number", AboveTypeLine: g.indentedComment("This is a byte in golang")}, nil
 		default:
 			return TypescriptType{ValueType: bs.Name()}, nil
 		}
@@ -642,32 +2055,56 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		return TypescriptType{
 			ValueType: "any",
 			AboveTypeLine: fmt.Sprintf("%s\n%s",
-				indentedComment("Embedded anonymous struct, please fix by naming it"),
-				indentedComment("eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed"),
+				g.indentedComment("Embedded anonymous struct, please fix by naming it"),
+				g.indentedComment("eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed"),
 			),
 		}, nil
 	case *types.Map:
 		// map[string][string] -> Record<string, string>
-		m := ty
-		keyType, err := g.typescriptType(m.Key())
+		keyType, valueType, aboveTypeLine, err := g.mapTypes(ty)
 		if err != nil {
-			return TypescriptType{}, xerrors.Errorf("map key: %w", err)
+			return TypescriptType{}, err
 		}
-		valueType, err := g.typescriptType(m.Elem())
-		if err != nil {
-			return TypescriptType{}, xerrors.Errorf("map key: %w", err)
+		if _, ok := ty.Key().Underlying().(*types.Struct); ok {
+			// Records cannot have object keys. These maps need custom
+			// marshaling, which is likely an array of key value pairs.
+			g.log.Warn(context.Background(), "map has a struct key, use `typescript:\",tuples\"` to generate an array of key value pairs",
+				slog.F("map", ty.String()),
+			)
 		}
-
-		aboveTypeLine := keyType.AboveTypeLine
-		if aboveTypeLine != "" && valueType.AboveTypeLine != "" {
-			aboveTypeLine = aboveTypeLine + "\n"
+		if key, ok := ty.Key().Underlying().(*types.Basic); ok && key.Info()&types.IsInteger > 0 {
+			// JSON object keys are always strings, so integer keys are
+			// formatted as strings on the wire.
+			comment := g.indentedComment(fmt.Sprintf("Keys are %s values formatted as strings.", types.TypeString(ty.Key(), g.qualifier)))
+			keyType = TypescriptType{ValueType: "string"}
+			if g.opts.NumericRecordKeys {
+				comment = g.indentedComment(fmt.Sprintf("Keys are %s values, but are strings at runtime.", types.TypeString(ty.Key(), g.qualifier)))
+				keyType = TypescriptType{ValueType: "number"}
+			}
+			aboveTypeLine = comment
+			if valueType.AboveTypeLine != "" {
+				aboveTypeLine += "\n" + valueType.AboveTypeLine
+			}
 		}
-		aboveTypeLine = aboveTypeLine + valueType.AboveTypeLine
 
-		return TypescriptType{
-			ValueType:     fmt.Sprintf("Record<%s, %s>", keyType.ValueType, valueType.ValueType),
+		record := func(value string) string {
+			if valueType.Optional && g.opts.NullableStyle == NullableUnion {
+				// Pointer values are marshaled as null.
+				value += " | null"
+			}
+			return fmt.Sprintf("Record<%s, %s>", keyType.ValueType, value)
+		}
+		ts := TypescriptType{
+			ValueType:     record(valueType.ValueType),
 			AboveTypeLine: aboveTypeLine,
-		}, nil
+		}
+		if valueType.GenericValue != "" {
+			// Keep the generic parameters of the parent, such as a struct
+			// with a map of itself.
+			ts.GenericValue = record(valueType.GenericValue)
+			ts.GenericTypes = valueType.GenericTypes
+		}
+		return ts, nil
 	case *types.Slice, *types.Array:
 		// Slice/Arrays are pretty much the same.
 		type hasElem interface {
@@ -681,25 +2118,91 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		case arr.Elem().String() == "byte":
 			// All byte arrays are strings on the typescript.
 			// Is this ok?
-			return TypescriptType{ValueType: "string"}, nil
+			ts := TypescriptType{ValueType: "string"}
+			if g.opts.ByteArrayEncoding != "" {
+				if fixed, ok := ty.(*types.Array); ok {
+					ts.AboveTypeLine = g.indentedComment(fmt.Sprintf("[%d]byte, %s encoded", fixed.Len(), g.opts.ByteArrayEncoding))
+				} else {
+					// encoding/json always encodes byte slices as base64.
+					ts.AboveTypeLine = g.indentedComment("[]byte, base64 encoded")
+				}
+			}
+			return ts, nil
 		default:
 			// By default, just do an array of the underlying type.
 			underlying, err := g.typescriptType(arr.Elem())
 			if err != nil {
 				return TypescriptType{}, xerrors.Errorf("array: %w", err)
 			}
-			return TypescriptType{ValueType: underlying.ValueType + "[]", AboveTypeLine: underlying.AboveTypeLine}, nil
+			nullable := underlying.Optional && g.opts.NullableStyle == NullableUnion
+			fixed, ok := ty.(*types.Array)
+			tuple := ok && g.opts.TupleArrays && fixed.Len() <= maxTupleLength
+			array := func(elem string) string {
+				if nullable {
+					// Pointer elements are marshaled as null.
+					elem += " | null"
+				}
+				if tuple {
+					elems := make([]string, fixed.Len())
+					for i := range elems {
+						elems[i] = elem
+					}
+					return "[" + strings.Join(elems, ", ") + "]"
+				}
+				if isCompound(elem) {
+					elem = "(" + elem + ")"
+				}
+				return elem + "[]"
+			}
+			ts := TypescriptType{
+				ValueType:     array(underlying.ValueType),
+				AboveTypeLine: underlying.AboveTypeLine,
+			}
+			if underlying.GenericValue != "" {
+				// Keep the generic parameters of the parent, such as a
+				// struct with a slice of itself.
+				ts.GenericValue = array(underlying.GenericValue)
+				ts.GenericTypes = underlying.GenericTypes
+			}
+			return ts, nil
 		}
 	case *types.Named:
 		n := ty
 
+		// Overrides take precedence over everything else.
+		if override, ok := g.opts.TypeOverrides[n.String()]; ok {
+			ts := TypescriptType{ValueType: override.Type}
+			if override.Comment != "" {
+				ts.AboveTypeLine = g.indentedComment(override.Comment)
+			}
+			return ts, nil
+		}
+
 		// These are external named types that we handle uniquely.
 		switch n.String() {
 		case "net/url.URL":
 			return TypescriptType{ValueType: "string"}, nil
+		case "net.IP", "net/netip.Addr":
+			return TypescriptType{ValueType: "string"}, nil
+		case "net/netip.Prefix":
+			// CIDR notation, eg "10.0.0.0/8".
+			return TypescriptType{ValueType: "string"}, nil
+		case "net.IPNet":
+			// net.IPNet does not implement encoding.TextMarshaler, so it is
+			// marshaled as a struct. Use netip.Prefix for a CIDR string.
+			return TypescriptType{ValueType: "{ IP: string, Mask: string }"}, nil
 		case "time.Time":
 			// We really should come up with a standard for time.
 			return TypescriptType{ValueType: "string"}, nil
+		case "time.Duration":
+			// Durations are marshaled as an integer, which is easy to
+			// mistake for milliseconds.
+			return TypescriptType{ValueType: "number", AboveTypeLine: g.indentedComment("time.Duration, in nanoseconds")}, nil
+		case "time.Weekday":
+			// Standard library enums have a fixed set of values.
+			return TypescriptType{ValueType: "0 | 1 | 2 | 3 | 4 | 5 | 6", AboveTypeLine: g.indentedComment("time.Weekday, 0 is Sunday")}, nil
+		case "time.Month":
+			return TypescriptType{ValueType: "1 | 2 | 3 | 4 | 5 | 6 | 7 | 8 | 9 | 10 | 11 | 12", AboveTypeLine: g.indentedComment("time.Month, 1 is January")}, nil
 		case "database/sql.NullTime":
 			return TypescriptType{ValueType: "string", Optional: true}, nil
 		case "github.com/coder/coder/codersdk.NullTime":
@@ -708,8 +2211,17 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 			return TypescriptType{ValueType: "string", Optional: true}, nil
 		case "github.com/google/uuid.UUID":
 			return TypescriptType{ValueType: "string"}, nil
+		case "encoding/json.Number":
+			// json.Number is marshaled as a bare number, but it is also
+			// unmarshaled from a quoted number.
+			if g.opts.JSONNumberUnion {
+				return TypescriptType{ValueType: "number | string", AboveTypeLine: g.indentedComment("json.Number, a number or a quoted number")}, nil
+			}
+			return TypescriptType{ValueType: "number", AboveTypeLine: g.indentedComment("json.Number, may be sent as a quoted number")}, nil
 		case "encoding/json.RawMessage":
-			return TypescriptType{ValueType: "Record<string, string>"}, nil
+			// Raw JSON can be any value. Fields with a known shape can set
+			// it with a `typescript:"<type>"` tag.
+			return TypescriptType{ValueType: "unknown", AboveTypeLine: g.indentedComment("json.RawMessage, arbitrary JSON")}, nil
 		}
 
 		// Then see if the type is defined elsewhere. If it is, we can just
@@ -718,7 +2230,7 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		name := n.Obj().Name()
 		genericName := ""
 		genericTypes := make(map[string]string)
-		if obj := g.pkg.Types.Scope().Lookup(name); obj != nil {
+		if obj := g.lookupNamed(n); obj != nil {
 			// Sweet! Using other typescript types as fields. This could be an
 			// enum or another struct
 			if args := n.TypeArgs(); args != nil && args.Len() > 0 {
@@ -755,8 +2267,8 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		// If it's a struct, just use the name of the struct type
 		if _, ok := n.Underlying().(*types.Struct); ok {
 			return TypescriptType{ValueType: "any", AboveTypeLine: fmt.Sprintf("%s\n%s",
-				indentedComment(fmt.Sprintf("Named type %q unknown, using \"any\"", n.String())),
-				indentedComment("eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed"),
+				g.indentedComment(fmt.Sprintf("Named type %q unknown, using \"any\"", n.String())),
+				g.indentedComment("eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed"),
 			)}, nil
 		}
 
@@ -765,7 +2277,11 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		if err != nil {
 			return TypescriptType{}, xerrors.Errorf("named underlying: %w", err)
 		}
-		ts.AboveTypeLine = indentedComment(fmt.Sprintf("This is likely an enum in an external package (%q)", n.String()))
+		if n.Obj().Pkg() == nil {
+			// Builtin types like 'error' have no package.
+			return ts, nil
+		}
+		ts.AboveTypeLine = g.indentedComment(fmt.Sprintf("This is likely an enum in an external package (%q)", n.String()))
 		return ts, nil
 	case *types.Pointer:
 		// Dereference pointers.
@@ -777,15 +2293,19 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		resp.Optional = true
 		return resp, nil
 	case *types.Interface:
-		// only handle the empty interface for now
+		// only handle the empty interface and error for now
 		intf := ty
+		if types.Identical(intf, types.Universe.Lookup("error").Type().Underlying()) {
+			// Errors are sent as their message.
+			return TypescriptType{ValueType: "string"}, nil
+		}
 		if intf.Empty() {
 			return TypescriptType{ValueType: "any",
-				AboveTypeLine: indentedComment("eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed")}, nil
+				AboveTypeLine: g.indentedComment("eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed")}, nil
 		}
 		return TypescriptType{}, xerrors.New("only empty interface types are supported")
 	case *types.TypeParam:
-		_, ok := ty.Underlying().(*types.Interface)
+		intf, ok := ty.Underlying().(*types.Interface)
 		if !ok {
 			// If it's not an interface, it is likely a usage of generics that
 			// we have not hit yet. Feel free to add support for it.
@@ -798,7 +2318,7 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 		pkgPath := ty.Obj().Pkg().Path()
 		name := strings.TrimPrefix(generic.String(), pkgPath+".")
 
-		referenced := g.pkg.Types.Scope().Lookup(name)
+		referenced := g.lookup(name)
 
 		if referenced == nil {
 			include, builtinString := g.isBuiltIn(name)
@@ -817,6 +2337,18 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 			}
 			// Include the builtin for this type to reference
 			g.builtins[name] = builtinString
+			g.since.builtin(name)
+		}
+
+		// If the constraint is a union that includes an optional type (eg a
+		// pointer), the field can be null. Match the union's optionality.
+		var optional bool
+		if terms, ok := constraintUnion(intf); ok {
+			var err error
+			_, optional, err = g.unionTypes(terms)
+			if err != nil {
+				return TypescriptType{}, xerrors.Errorf("constraint %q: %w", name, err)
+			}
 		}
 
 		return TypescriptType{
@@ -826,7 +2358,7 @@ func (g *Generator) typescriptType(ty types.Type) (TypescriptType, error) {
 			GenericValue:  ty.Obj().Name(),
 			ValueType:     name,
 			AboveTypeLine: "",
-			Optional:      false,
+			Optional:      optional,
 		}, nil
 	}
 
@@ -855,6 +2387,6 @@ func (Generator) isBuiltIn(name string) (bool, string) {
 	}
 }
 
-func indentedComment(comment string) string {
-	return fmt.Sprintf("%s// %s", indent, comment)
+func (g *Generator) indentedComment(comment string) string {
+	return fmt.Sprintf("%s// %s", g.opts.Indent, comment)
 }
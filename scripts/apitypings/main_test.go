@@ -7,14 +7,28 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
 )
 
+// To update the golden files:
+// make update-golden-files
+var updateGoldenFiles = flag.Bool("update", false, "update the .ts golden files in testdata")
+
+// TestGeneration generates each fixture package in testdata, and compares
+// the output to the golden file of the fixture.
 func TestGeneration(t *testing.T) {
 	t.Parallel()
 	files, err := os.ReadDir("testdata")
@@ -29,15 +43,810 @@ func TestGeneration(t *testing.T) {
 		t.Run(f.Name(), func(t *testing.T) {
 			t.Parallel()
 			dir := filepath.Join(".", "testdata", f.Name())
-			output, err := Generate("./" + dir)
+			output, err := Generate("./"+dir, Options{})
 			require.NoErrorf(t, err, "generate %q", dir)
 
 			golden := filepath.Join(dir, f.Name()+".ts")
+			if *updateGoldenFiles {
+				t.Logf("update golden file %s", golden)
+				err = os.WriteFile(golden, []byte(strings.TrimSpace(output)+"\n"), 0o600)
+				require.NoError(t, err, "update golden file")
+			}
+
 			expected, err := os.ReadFile(golden)
-			require.NoErrorf(t, err, "read file %s", golden)
+			require.NoErrorf(t, err, "read file %s, run \"make update-golden-files\" and commit the changes", golden)
 			expectedString := strings.TrimSpace(string(expected))
 			output = strings.TrimSpace(output)
-			require.Equal(t, expectedString, output, "matched output")
+			require.Equal(t, expectedString, output, "golden file mismatch: %s, run \"make update-golden-files\", verify and commit the changes", golden)
 		})
 	}
 }
+
+func TestGenerateIncludeTests(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(".", "testdata", "testfiles")
+
+	output, err := Generate("./"+dir, Options{})
+	require.NoError(t, err)
+	require.NotContains(t, output, "ExampleResponse")
+
+	output, err = Generate("./"+dir, Options{IncludeTests: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "export interface ExampleResponse {\n  readonly shapes: Shape[]\n}")
+	require.Equal(t, 1, strings.Count(output, "export interface Shape {"), "types generated once")
+}
+
+func TestGenerateIndent(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(".", "testdata", "indent")
+
+	output, err := Generate("./"+dir, Options{Indent: "\t"})
+	require.NoError(t, err)
+	require.Contains(t, output, "\n\treadonly name: string\n")
+	require.Contains(t, output, "\n\treadonly labels: Record<string, string>\n")
+	require.Contains(t, output, "\n\t// Embedded anonymous struct, please fix by naming it\n")
+	require.NotContains(t, output, "  ", "no spaces used for indentation")
+}
+
+func TestGenerateNoSourceComments(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(".", "testdata", "enums")
+
+	output, err := Generate("./"+dir, Options{NoSourceComments: true})
+	require.NoError(t, err)
+	require.NotContains(t, output, "// From")
+	require.Contains(t, output, "export type Enums = Enum[]\n")
+	require.Contains(t, output, "export type Enum = \"bar\" | \"baz\" | \"foo\" | \"qux\"\n")
+}
+
+func TestGenerateEnumRegistry(t *testing.T) {
+	t.Parallel()
+	files, err := os.ReadDir("testdata")
+	require.NoError(t, err, "read dir")
+
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		f := f
+		t.Run(f.Name(), func(t *testing.T) {
+			t.Parallel()
+			dir := filepath.Join(".", "testdata", f.Name())
+			codeBlocks, err := GenerateFromDirectory(context.Background(), slog.Make(), "./"+dir, Options{EmitEnumRegistry: true})
+			require.NoError(t, err)
+
+			for name := range codeBlocks.Enums {
+				require.Regexp(t, `export type AnyEnum = .*\b`+name+`\b`, codeBlocks.EnumRegistry)
+				require.Contains(t, codeBlocks.EnumRegistry, `"`+name+`"`)
+			}
+			if len(codeBlocks.Enums) == 0 {
+				require.Contains(t, codeBlocks.EnumRegistry, "export type AnyEnum = never\n")
+			}
+		})
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "typesGenerated.ts")
+
+	// The file does not exist yet.
+	require.Error(t, checkOutput(path, "export type A = string\n"))
+
+	require.NoError(t, writeOutput(path, "export type A = string\n"))
+	require.NoError(t, checkOutput(path, "export type A = string\n"))
+	require.ErrorContains(t, checkOutput(path, "export type A = number\n"), "out of date")
+
+	require.NoError(t, writeOutput(path, "export type A = number\n"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "export type A = number\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestGenerateNullableStyle(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "nullable")
+
+	t.Run("Comment", func(t *testing.T) {
+		t.Parallel()
+		output, err := Generate(dir, Options{NullableStyle: NullableComment})
+		require.NoError(t, err)
+		require.Contains(t, output, "  // Null when unset, never omitted.\n  readonly nickname?: string\n")
+		require.Contains(t, output, "  // Omitted when empty, never null.\n  readonly bio?: string\n")
+		require.Contains(t, output, "  // Omitted when empty, never null.\n  readonly avatar_url?: string\n")
+		require.Contains(t, output, "  readonly name: string\n")
+	})
+
+	t.Run("Union", func(t *testing.T) {
+		t.Parallel()
+		output, err := Generate(dir, Options{NullableStyle: NullableUnion})
+		require.NoError(t, err)
+		require.Contains(t, output, "  readonly nickname: string | null\n")
+		require.Contains(t, output, "  readonly bio?: string\n")
+		require.Contains(t, output, "  readonly avatar_url?: string\n")
+		require.Contains(t, output, "  readonly not_null: string\n")
+		require.Contains(t, output, "  readonly workspaces: (Workspace | null)[]\n")
+	})
+
+	t.Run("Optional", func(t *testing.T) {
+		t.Parallel()
+		output, err := Generate(dir, Options{})
+		require.NoError(t, err)
+		// Array elements cannot be optional, so null elements are not
+		// represented.
+		require.Contains(t, output, "  readonly workspaces: Workspace[]\n")
+	})
+}
+
+func TestGenerateNamespaceByPrefix(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "namespace")
+
+	output, err := Generate(dir, Options{NamespacePrefixes: []string{"Workspace"}})
+	require.NoError(t, err)
+
+	// Matching types are moved into the namespace, without the prefix.
+	require.Contains(t, output, "export namespace Workspace {\n")
+	require.Contains(t, output, "  export interface Build {\n    readonly status: Workspace.Status\n    readonly template: Template\n  }\n")
+	require.Contains(t, output, `  export type Status = "running" | "stopped"`+"\n")
+	require.Contains(t, output, `  export const Statuses: Workspace.Status[] = ["running", "stopped"]`+"\n")
+	require.NotContains(t, output, "WorkspaceBuild")
+	require.NotContains(t, output, "WorkspaceStatus")
+
+	// Other types stay top-level, and reference the namespace.
+	require.Contains(t, output, "export interface Workspace {\n  readonly latest_build: Workspace.Build\n}\n")
+	require.Contains(t, output, "export interface Template {\n  readonly workspaces: Workspace[]\n}\n")
+}
+
+func TestGenerateTypeOverrides(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(".", "testdata", "overrides")
+
+	config, err := loadConfig(filepath.Join(dir, "overrides.yaml"))
+	require.NoError(t, err)
+
+	output, err := Generate("./"+dir, Options{TypeOverrides: config.Overrides})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // Formatted as YYYY-MM-DD\n  readonly expires_at: string\n")
+	require.Contains(t, output, "  // Formatted as YYYY-MM-DD\n  readonly starts_at?: string\n")
+	require.Contains(t, output, "  // Formatted as YYYY-MM-DD\n  readonly holidays: string[]\n")
+	require.NotContains(t, output, "export interface Date", "overridden type is not declared")
+}
+
+func TestGeneratePostProcess(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "enums")
+
+	output, err := Generate(dir, Options{
+		PostProcess: func(output string) (string, error) {
+			return strings.ReplaceAll(output, "// From", strings.ToUpper("// From")), nil
+		},
+	})
+	require.NoError(t, err)
+	require.Contains(t, output, "// FROM codersdk/enums.go")
+	require.NotContains(t, output, "// From")
+
+	_, err = Generate(dir, Options{
+		PostProcess: func(string) (string, error) {
+			return "", xerrors.New("bad format")
+		},
+	})
+	require.ErrorContains(t, err, "bad format")
+}
+
+func TestGenerateByteArrayEncoding(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "bytes")
+
+	output, err := Generate(dir, Options{ByteArrayEncoding: "hex"})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // [32]byte, hex encoded\n  readonly hash: string\n")
+	require.Contains(t, output, "  // []byte, base64 encoded\n  readonly data: string\n")
+}
+
+func TestGenerateInt64Style(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "int64s")
+
+	output, err := Generate(dir, Options{Int64Style: Int64String})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // int64, encoded as a string\n  readonly limit: string\n")
+	require.Contains(t, output, "  // uint64, encoded as a string\n  readonly bytes: string\n")
+	require.Contains(t, output, "  readonly used: number\n", "int32 is a safe integer")
+
+	output, err = Generate(dir, Options{Int64Style: Int64Brand})
+	require.NoError(t, err)
+	require.Contains(t, output, "export type Int64 = number & { __brand: \"Int64\" }")
+	require.Contains(t, output, "  readonly limit: Int64\n")
+	require.Contains(t, output, "  readonly history: Int64[]\n")
+	require.Contains(t, output, "  readonly used: number\n", "int32 is a safe integer")
+	// Fields with `json:",string"` are strings in every style.
+	require.Contains(t, output, "  readonly limit_text: string\n")
+}
+
+func TestGenerateJSONNumber(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "jsonnumber")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // json.Number, may be sent as a quoted number\n  readonly value: number\n")
+
+	output, err = Generate(dir, Options{JSONNumberUnion: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // json.Number, a number or a quoted number\n  readonly value: number | string\n")
+	require.Contains(t, output, "  readonly samples: (number | string)[]\n")
+}
+
+func TestGenerateFloatEnums(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "floatenums")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	// Floats are not rounded, and are formatted like encoding/json.
+	require.Contains(t, output, "export type Tier = 0 | 1e-7 | 0.1 | 0.123456789 | 1.25\n")
+	require.Contains(t, output, `readonly tier_quote: "0" | "0.1" | "0.123456789" | "1.25" | "1e-7"`)
+}
+
+func TestGenerateDurations(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "durations")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // time.Duration, in nanoseconds\n  readonly timeout: number\n")
+	require.Contains(t, output, "  // time.Duration, in nanoseconds\n  readonly grace?: number\n")
+	require.Contains(t, output, "  // time.Duration, in milliseconds\n  readonly interval: number\n")
+}
+
+func TestGenerateRawJSON(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "rawjson")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // json.RawMessage, arbitrary JSON\n  readonly payload: unknown\n")
+	// Pointers are still optional.
+	require.Contains(t, output, "  // json.RawMessage, arbitrary JSON\n  readonly previous?: unknown\n")
+	require.Contains(t, output, "  // json.RawMessage, arbitrary JSON\n  readonly batch: unknown[]\n")
+	// Commas in the type of the tag are not option separators.
+	require.Contains(t, output, "  readonly fields: Record<string, string>\n")
+
+	output, err = Generate(dir, Options{NullableStyle: NullableUnion})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // json.RawMessage, arbitrary JSON\n  readonly previous: unknown | null\n")
+}
+
+func TestGenerateMixedEnums(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "mixedenums")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	// Integer enums are unquoted and sorted numerically.
+	require.Contains(t, output, "export type LogLevel = 0 | 1 | 2 | 3 | 4 | 5 | 6 | 7 | 8 | 9 | 10\n")
+	require.Contains(t, output, "export const LogLevels: LogLevel[] = [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10]\n")
+	require.Contains(t, output, `export type LogSource = "agent" | "provisioner"`+"\n")
+}
+
+func TestGenerateEscapedEnums(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "escaping")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, `export type Separator = "<br>" | "\"" | "\\" | "\n" | "\u0007" | `)
+
+	// Every literal is a valid string that decodes to the Go value.
+	_, union, ok := strings.Cut(output, "export type Separator = ")
+	require.True(t, ok)
+	union, _, _ = strings.Cut(union, "\n")
+	var values []string
+	require.NoError(t, json.Unmarshal([]byte("["+strings.ReplaceAll(union, " | ", ", ")+"]"), &values))
+	require.ElementsMatch(t, []string{`"`, `\`, "\n", "\a", "<br>", "a separator that is long enough to be shortened by constant.Value.String"}, values)
+}
+
+func TestGenerateStdlibEnums(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "stdenums")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // time.Weekday, 0 is Sunday\n  readonly start: 0 | 1 | 2 | 3 | 4 | 5 | 6\n")
+	require.Contains(t, output, "  readonly days: (0 | 1 | 2 | 3 | 4 | 5 | 6)[]\n")
+	require.Contains(t, output, "  readonly months: (1 | 2 | 3 | 4 | 5 | 6 | 7 | 8 | 9 | 10 | 11 | 12)[]\n")
+}
+
+func TestGenerateIntegerMapKeys(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "intkeys")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // Keys are int values formatted as strings.\n  readonly ports: Record<string, Port>\n")
+	require.Contains(t, output, "  // Keys are Priority values formatted as strings.\n  readonly by_priority: Record<string, Port>\n")
+
+	output, err = Generate(dir, Options{NumericRecordKeys: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "  // Keys are int values, but are strings at runtime.\n  readonly ports: Record<number, Port>\n")
+	require.Contains(t, output, "  readonly by_name: Record<string, Port>\n")
+}
+
+func TestGenerateEmbeddedChain(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "embedchain")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	// Every level extends the level it embeds, so the fields of Base are
+	// reachable from Leaf.
+	require.Contains(t, output, "export interface Leaf extends Middle {\n  readonly value: number\n}")
+	require.Contains(t, output, "export interface Middle extends Base {\n  readonly name: string\n}")
+	require.Contains(t, output, "export interface Base {\n  readonly id: string\n}")
+	// Ignored levels are not generated, so their fields are inlined.
+	require.NotContains(t, output, "IgnoredMiddle")
+	require.Contains(t, output, "export interface IgnoredLeaf extends Base {\n  readonly hidden: string\n  readonly value: number\n}")
+}
+
+func TestGenerateFlattenEmbeds(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "flatten")
+
+	output, err := Generate(dir, Options{FlattenEmbeds: true})
+	require.NoError(t, err)
+	// Embedded fields are placed where the struct is embedded, including
+	// the fields the embedded struct embeds.
+	require.Contains(t, output, `export interface Workspace {
+  readonly id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly audited_by: string
+  readonly name: string
+  readonly owner_id: string
+  readonly owner_name: string
+  readonly status: string
+}`)
+	require.NotContains(t, output, "extends")
+
+	output, err = Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "export interface Workspace extends Audited, Partial<Owner> {\n")
+}
+
+func TestGenerateComposedConstraints(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "constraints")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	// Embedded constraint interfaces are flattened into their types.
+	require.Contains(t, output, "export type Scalar = number | string | boolean\n")
+	require.Contains(t, output, "export type Value = number | string | boolean | string[]")
+	// Embedding several elements intersects their types.
+	require.Contains(t, output, "export type Named = string\n")
+	require.Contains(t, output, "export type Empty = never\n")
+}
+
+func TestGenerateFieldDocs(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "fielddocs")
+
+	output, err := Generate(dir, Options{FieldDocs: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "  /** ID is the workspace's unique identifier. */\n  readonly id: string\n")
+	require.Contains(t, output, `  /**
+   * Deadline is the time the workspace stops.
+   *
+   * It is extended by activity, see
+   * https://coder.com/docs/workspaces.
+   */
+  readonly deadline: string
+`)
+	// Comments about the type stay directly above the field.
+	require.Contains(t, output, "  /** Ttl is how long the workspace runs for. */\n  // time.Duration, in nanoseconds\n  readonly ttl: number\n")
+	require.Contains(t, output, `  /** Glob matches paths such as "a/*\/b", and "*\/" must not end the comment. */`)
+	require.Contains(t, output, "  /** Owner and Name are both documented by this comment. */\n  readonly Name: string\n")
+	// Directives and trailing comments are not documentation.
+	require.Contains(t, output, "  readonly Name: string\n  readonly template: string\n  readonly region: string\n")
+
+	output, err = Generate(dir, Options{})
+	require.NoError(t, err)
+	require.NotContains(t, output, "/**")
+}
+
+func TestGenerateCrossPackageEmbed(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "crossembed")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	// Generated types are extended in any package, and the fields of types
+	// from other packages, which are not generated, are inlined.
+	require.Contains(t, output, "export interface Event extends Actor {\n  readonly created_at: string\n  readonly updated_at: string\n  readonly action: string\n}")
+	require.NotContains(t, output, "Timestamps")
+}
+
+func TestGenerateMultiplePackages(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "multipkg")
+
+	output, err := Generate(dir, Options{AdditionalPackages: []string{dir + "/agentsdk"}})
+	require.NoError(t, err)
+	// Types in other packages are referenced by name.
+	require.Contains(t, output, "// From codersdk/agentsdk/agentsdk.go\nexport interface Manifest {\n  readonly agent: WorkspaceAgent\n  readonly health: Health\n}")
+	require.Contains(t, output, "// From codersdk/agentsdk/agentsdk.go\nexport interface PostLifecycleRequest {\n  readonly state: WorkspaceAgentLifecycle\n}")
+	require.Contains(t, output, "// From codersdk/multipkg.go\nexport interface WorkspaceAgent {")
+	require.Contains(t, output, `export type Health = "connected" | "disconnected"`)
+	// Constants of the type in other packages are not values of the enum.
+	require.Contains(t, output, `export type WorkspaceAgentLifecycle = "created" | "ready"`)
+
+	_, err = Generate(dir, Options{AdditionalPackages: []string{dir + "/conflict"}})
+	require.ErrorContains(t, err, `type "WorkspaceAgent" is declared in both`)
+}
+
+func TestGenerateTupleArrays(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "tuplearrays")
+
+	output, err := Generate(dir, Options{TupleArrays: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly rgb: [number, number, number]\n")
+	require.Contains(t, output, "  readonly history: number[]\n", "slices are not tuples")
+	require.Contains(t, output, "  readonly samples: number[]\n", "long arrays are not tuples")
+	require.Contains(t, output, "  readonly names: [Name, Name]\n")
+
+	output, err = Generate(dir, Options{TupleArrays: true, NullableStyle: NullableUnion})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly names: [Name | null, Name | null]\n")
+}
+
+func TestGenerateUUIDArrays(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "uuidarrays")
+
+	// uuid.UUID is a [16]byte, but it is marshaled as a string, so fixed
+	// size arrays of UUIDs are not byte arrays.
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly ids: string[]\n")
+
+	output, err = Generate(dir, Options{TupleArrays: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly ids: [string, string, string, string]\n")
+	require.Contains(t, output, "  readonly others: string[]\n")
+}
+
+func TestGenerateBrandNamedStrings(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "brands")
+
+	output, err := Generate(dir, Options{BrandNamedStrings: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "export type Username = string & { __brand: \"Username\" }\n")
+	require.NotContains(t, output, "Usernames")
+	require.Contains(t, output, "  readonly username: Username\n")
+	require.Contains(t, output, "  readonly previous: Username[]\n")
+	// Enums are not branded.
+	require.Contains(t, output, "export type Status = \"active\" | \"suspended\"\n")
+}
+
+func TestGenerateEnumSourceOrder(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "enumorder")
+
+	output, err := Generate(dir, Options{EnumSourceOrder: true})
+	require.NoError(t, err)
+	require.Contains(t, output, `export const BuildStatuses: BuildStatus[] = ["pending", "starting", "running", "stopping", "done"]`+"\n")
+	require.Contains(t, output, `export const Levels: Level[] = ["high", "medium", "low"]`)
+	// The union is still sorted.
+	require.Contains(t, output, `export type BuildStatus = "done" | "pending" | "running" | "starting" | "stopping"`+"\n")
+}
+
+func TestGenerateRealEnums(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "realenums")
+
+	output, err := Generate(dir, Options{EnumSourceOrder: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "export enum BuildReason {\n  Initiator = \"initiator\",\n  Autostart = \"autostart\",\n  Autostop = \"autostop\",\n}\n")
+	require.Contains(t, output, "export const BuildReasons: BuildReason[] = [BuildReason.Initiator, BuildReason.Autostart, BuildReason.Autostop]\n")
+	// Enums without the marker are still unions.
+	require.Contains(t, output, `export type LogLevel = "debug" | "info"`+"\n")
+}
+
+func TestGenerateDiscriminatedUnion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Narrowable", func(t *testing.T) {
+		t.Parallel()
+		dir := "./" + filepath.Join(".", "testdata", "discriminator")
+		output, err := Generate(dir, Options{})
+		require.NoError(t, err)
+		require.Contains(t, output, "// Parameter is discriminated by its \"type\" field.\nexport type Parameter = StringParameter | NumberParameter | ListParameter")
+		require.Contains(t, output, "  readonly type: \"string\"\n")
+		require.Contains(t, output, "  readonly type: \"number\"\n")
+	})
+
+	t.Run("MissingField", func(t *testing.T) {
+		t.Parallel()
+		// The package must be in the module to be loaded.
+		dir, err := os.MkdirTemp(".", "discriminator-test-")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dir)
+		})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte(`package codersdk
+
+type A struct {
+	Type string `+"`json:\"type\" typescript:\"\\\"a\\\"\"`"+`
+}
+
+type B struct {
+	Name string `+"`json:\"name\"`"+`
+}
+
+// @typescript-discriminator:type
+type AB interface {
+	A | B
+}
+`), 0o600))
+
+		_, err = Generate("./"+dir, Options{})
+		require.ErrorContains(t, err, `missing discriminator field "type"`)
+	})
+}
+
+func TestGenerateNestedMaps(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "nestedmaps")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly counts: Record<string, Record<string, number>>\n")
+	require.Contains(t, output, "  readonly deep: Record<string, Record<string, Record<string, boolean>>>\n")
+	require.Contains(t, output, "  // eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed\n  readonly values: Record<string, Record<string, any>>\n")
+	require.Contains(t, output, "  readonly ptrs: Record<string, Record<string, Resource>>\n")
+
+	output, err = Generate(dir, Options{NullableStyle: NullableUnion})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly ptrs: Record<string, Record<string, Resource | null>>\n")
+	require.Contains(t, output, "  readonly counts: Record<string, Record<string, number>>\n")
+}
+
+func TestGenerateSlicesOfMaps(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "slicemaps")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly rows: Record<string, number>[]\n")
+	require.Contains(t, output, "  readonly columns: Record<string, number[]>\n")
+	require.Contains(t, output, "  readonly grid: Record<string, number>[][]\n")
+	require.Contains(t, output, "  readonly nested: Record<string, Record<string, number>[]>[]\n")
+	// Comments of the innermost type are kept.
+	require.Contains(t, output, "  // eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed\n  readonly values: Record<string, any>[]\n")
+	require.Contains(t, output, "  // eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed\n  readonly any_lists: Record<string, any[]>\n")
+
+	output, err = Generate(dir, Options{NullableStyle: NullableUnion})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly resources: Record<string, Resource | null>[]\n")
+	require.Contains(t, output, "  readonly by_resource: Record<string, (Resource | null)[]>\n")
+}
+
+func TestGenerateOptionalEnums(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "optionalenums")
+
+	// Enums are named types, but follow the nullable style like
+	// primitives.
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly status?: WorkspaceStatus\n")
+	require.Contains(t, output, "  readonly previous?: WorkspaceStatus\n")
+	require.Contains(t, output, "  readonly current: WorkspaceStatus\n")
+
+	output, err = Generate(dir, Options{NullableStyle: NullableUnion})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly status: WorkspaceStatus | null\n")
+	require.Contains(t, output, "  readonly previous?: WorkspaceStatus\n")
+	require.Contains(t, output, "  readonly current: WorkspaceStatus\n")
+	require.Contains(t, output, "  readonly history: (WorkspaceStatus | null)[]\n")
+	require.Contains(t, output, "  readonly by_agent: Record<string, WorkspaceStatus | null>\n")
+}
+
+func TestGenerateExplicitUndefined(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "nullable")
+
+	output, err := Generate(dir, Options{ExplicitUndefined: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly nickname?: string | undefined\n")
+	require.Contains(t, output, "  readonly bio?: string | undefined\n")
+	require.Contains(t, output, "  readonly name: string\n", "required fields are unchanged")
+
+	output, err = Generate(dir, Options{ExplicitUndefined: true, NullableStyle: NullableUnion})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly nickname: string | null\n", "null fields are not optional")
+	require.Contains(t, output, "  readonly bio?: string | undefined\n")
+
+	output, err = Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly nickname?: string\n")
+	require.NotContains(t, output, "undefined")
+}
+
+func TestGenerateForceOptional(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "nullable")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly role?: string\n")
+
+	output, err = Generate(dir, Options{NullableStyle: NullableUnion})
+	require.NoError(t, err)
+	require.Contains(t, output, "  readonly role?: string\n", "forced optional fields are not null")
+}
+
+func TestGenerateCustomMarshaler(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "marshaler")
+
+	output, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "// Secret implements json.Marshaler, this type might not match the JSON.\nexport interface Secret {\n")
+	// Raw types replace the generated type, without a warning.
+	require.Contains(t, output, "export type Counts = Record<string, number>\n")
+	require.NotContains(t, output, "// Counts implements json.Marshaler")
+
+	output, err = Generate(dir, Options{NoSourceComments: true})
+	require.NoError(t, err)
+	require.Contains(t, output, "// Secret implements json.Marshaler, this type might not match the JSON.\nexport interface Secret {\n")
+}
+
+func TestGenerateEmitStyle(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "enums")
+
+	module, err := Generate(dir, Options{EmitStyle: EmitModule})
+	require.NoError(t, err)
+	expected, err := Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Equal(t, expected, module, "module is the default")
+
+	dts, err := Generate(dir, Options{EmitStyle: EmitDTS})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(dts, "\n// Code generated by 'make site/src/api/typesGenerated.ts'. DO NOT EDIT.\n\ndeclare module \"api/typesGenerated\" {\n"), dts)
+	require.True(t, strings.HasSuffix(dts, "\n}"), dts)
+	require.Contains(t, dts, "  export type Enum = \"bar\" | \"baz\" | \"foo\" | \"qux\"\n")
+	// Ambient consts cannot have initializers.
+	require.Contains(t, dts, "  export const Enums: Enum[]\n")
+	require.NotContains(t, dts, " = [")
+
+	dts, err = Generate(dir, Options{EmitStyle: EmitDTS, ModuleName: "@coder/api", Indent: "\t"})
+	require.NoError(t, err)
+	require.Contains(t, dts, "declare module \"@coder/api\" {\n\t// From codersdk/enums.go\n")
+}
+
+func TestGenerateInterfaceUnions(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "interfaces")
+
+	output, err := Generate(dir, Options{InterfaceUnions: true})
+	require.NoError(t, err)
+	// Square implements Shape with a pointer receiver.
+	require.Contains(t, output, "export type Shape = Circle | Square\n")
+	require.Contains(t, output, "  readonly shapes: Shape[]\n")
+
+	output, err = Generate(dir, Options{})
+	require.NoError(t, err)
+	require.Contains(t, output, "export type Shape = unknown\n")
+}
+
+func TestGeneratePointerMaps(t *testing.T) {
+	t.Parallel()
+	dir := "./" + filepath.Join(".", "testdata", "pointermaps")
+
+	t.Run("Union", func(t *testing.T) {
+		t.Parallel()
+		output, err := Generate(dir, Options{NullableStyle: NullableUnion})
+		require.NoError(t, err)
+		// A pointer to a map makes the field nullable, pointer values make
+		// the values nullable.
+		require.Contains(t, output, "  readonly pointer_to_map: Record<string, Foo> | null\n")
+		require.Contains(t, output, "  readonly map_of_pointers: Record<string, Foo | null>\n")
+		require.Contains(t, output, "  readonly pointer_to_map_of_pointers: Record<string, Foo | null> | null\n")
+		require.Contains(t, output, "  readonly map_of_pointer_to_map: Record<string, Record<string, Foo> | null>\n")
+		require.Contains(t, output, "  readonly omitted_map?: Record<string, Foo | null>\n")
+		require.Contains(t, output, "  readonly pairs: Array<[Key, Foo | null]>\n")
+	})
+
+	t.Run("Optional", func(t *testing.T) {
+		t.Parallel()
+		output, err := Generate(dir, Options{})
+		require.NoError(t, err)
+		require.Contains(t, output, "  readonly pointer_to_map?: Record<string, Foo>\n")
+		require.Contains(t, output, "  readonly map_of_pointers: Record<string, Foo>\n")
+		require.Contains(t, output, "  readonly pointer_to_map_of_pointers?: Record<string, Foo>\n")
+		require.Contains(t, output, "  readonly pairs: Array<[Key, Foo]>\n")
+	})
+}
+
+func TestGenerateSince(t *testing.T) {
+	t.Parallel()
+
+	// The package must be in the module to be loaded.
+	dir, err := os.MkdirTemp(".", "since-test-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	// Files are written with a time in the past, unless they are changed
+	// after the types were generated.
+	past := time.Now().Add(-time.Hour)
+	write := func(name, source string, modified time.Time) {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte("package codersdk\n\n"+source), 0o600))
+		require.NoError(t, os.Chtimes(file, modified, modified))
+	}
+	write("a.go", "type A struct {\n\tName string `json:\"name\"`\n}\n", past)
+	write("b.go", "type B struct {\n\tName string `json:\"name\"`\n\tC    C      `json:\"c\"`\n}\n", past)
+	write("c.go", "type C struct {\n\tName string `json:\"name\"`\n}\n", past)
+
+	since := filepath.Join(dir, "generated.ts")
+	opts := Options{Since: since}
+	_, err = Generate("./"+dir, opts)
+	require.NoError(t, err)
+
+	// Mark the cached code blocks, so reused blocks can be told apart from
+	// regenerated ones.
+	cachePath := sinceCachePath(since)
+	markCache := func() {
+		cache, err := os.ReadFile(cachePath)
+		require.NoError(t, err)
+		cache = bytes.ReplaceAll(cache, []byte("readonly name: string"), []byte("readonly name: cached"))
+		require.NoError(t, os.WriteFile(cachePath, cache, 0o600))
+	}
+	markCache()
+
+	// Nothing changed, so the packages are not loaded. If they were, the
+	// invalid source would fail to load.
+	write("a.go", "invalid", past)
+	output, err := Generate("./"+dir, opts)
+	require.NoError(t, err)
+	require.Contains(t, output, "export interface A {\n  readonly name: cached\n}")
+	require.Contains(t, output, "export interface B {\n  readonly name: cached\n  readonly c: C\n}")
+	require.Contains(t, output, "export interface C {\n  readonly name: cached\n}")
+	write("a.go", "type A struct {\n\tName string `json:\"name\"`\n}\n", past)
+
+	// Changing C regenerates B, which depends on it.
+	write("c.go", "type C struct {\n\tName string `json:\"name\"`\n\tID   string `json:\"id\"`\n}\n", time.Now())
+	output, err = Generate("./"+dir, opts)
+	require.NoError(t, err)
+	require.Contains(t, output, "export interface A {\n  readonly name: cached\n}")
+	require.Contains(t, output, "export interface B {\n  readonly name: string\n  readonly c: C\n}")
+	require.Contains(t, output, "export interface C {\n  readonly name: string\n  readonly id: string\n}")
+
+	// Blocks generated by another version of the generator are not reused.
+	markCache()
+	data, err := os.ReadFile(cachePath)
+	require.NoError(t, err)
+	var cache sinceCache
+	require.NoError(t, json.Unmarshal(data, &cache))
+	cache.Generator = "other"
+	data, err = json.Marshal(cache)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cachePath, data, 0o600))
+	output, err = Generate("./"+dir, opts)
+	require.NoError(t, err)
+	require.NotContains(t, output, "cached")
+}
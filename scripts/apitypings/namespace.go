@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var namespacedDeclRegex = regexp.MustCompile(`(?m)^(export (?:interface|type|const) )\w+\.(\w+)`)
+
+// namespaceByPrefix moves types that start with one of the prefixes into a
+// namespace named by the prefix. The prefix is trimmed from the name of the
+// type, and all references to the type are rewritten. A type named exactly
+// as the prefix stays top-level, and merges with the namespace.
+//
+//	WorkspaceBuild -> Workspace.Build
+func namespaceByPrefix(t *TypescriptTypes, prefixes []string, indent string) {
+	// Match the longest prefix first, so "WorkspaceAgent" is preferred over
+	// "Workspace".
+	prefixes = append([]string{}, prefixes...)
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	namespaceOf := func(name string) (string, bool) {
+		for _, prefix := range prefixes {
+			if prefix == "" || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			// Only split on word boundaries, so "Workspaces" is not in the
+			// "Workspace" namespace.
+			r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+			if unicode.IsUpper(r) {
+				return prefix, true
+			}
+		}
+		return "", false
+	}
+
+	// renames maps each identifier to its name in the namespace.
+	renames := make(map[string]string)
+	// members are the code blocks in each namespace.
+	members := make(map[string]map[string]string)
+	for _, blocks := range []map[string]string{t.Types, t.Enums, t.Generics} {
+		for name, block := range blocks {
+			namespace, ok := namespaceOf(name)
+			if !ok {
+				continue
+			}
+			renames[name] = namespace + "." + strings.TrimPrefix(name, namespace)
+			if _, ok := t.Enums[name]; ok {
+				plural := enumPluralName(name)
+				renames[plural] = namespace + "." + strings.TrimPrefix(plural, namespace)
+			}
+			if members[namespace] == nil {
+				members[namespace] = make(map[string]string)
+			}
+			members[namespace][name] = block
+			delete(blocks, name)
+		}
+	}
+	if len(renames) == 0 {
+		return
+	}
+
+	identifiers := make([]string, 0, len(renames))
+	for name := range renames {
+		identifiers = append(identifiers, regexp.QuoteMeta(name))
+	}
+	// Longest first, so the longest identifier is matched.
+	sort.Slice(identifiers, func(i, j int) bool {
+		return len(identifiers[i]) > len(identifiers[j])
+	})
+	referenceRegex := regexp.MustCompile(`\b(` + strings.Join(identifiers, "|") + `)\b`)
+	rewrite := func(block string) string {
+		return referenceRegex.ReplaceAllStringFunc(block, func(name string) string {
+			return renames[name]
+		})
+	}
+
+	for _, blocks := range []map[string]string{t.Types, t.Enums, t.Generics} {
+		for name, block := range blocks {
+			blocks[name] = rewrite(block)
+		}
+	}
+	t.EnumRegistry = rewrite(t.EnumRegistry)
+
+	if t.Namespaces == nil {
+		t.Namespaces = make(map[string]string)
+	}
+	for namespace, blocks := range members {
+		names := make([]string, 0, len(blocks))
+		for name := range blocks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var s strings.Builder
+		_, _ = s.WriteString(fmt.Sprintf("export namespace %s {\n", namespace))
+		for i, name := range names {
+			if i > 0 {
+				_, _ = s.WriteRune('\n')
+			}
+			// Declarations use the name without the namespace.
+			block := namespacedDeclRegex.ReplaceAllString(rewrite(blocks[name]), "$1$2")
+			for _, line := range strings.Split(strings.TrimRight(block, "\n"), "\n") {
+				if line != "" {
+					line = indent + line
+				}
+				_, _ = s.WriteString(line + "\n")
+			}
+		}
+		_, _ = s.WriteString("}\n")
+		t.Namespaces[namespace] = s.String()
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// writeOutput atomically replaces the file at path with output. The output
+// is written to a temporary file in the same directory, which is renamed
+// over path, so readers never see a partially written file.
+func writeOutput(path string, output string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return xerrors.Errorf("create temp file: %w", err)
+	}
+	defer func() {
+		// Does nothing once the file is renamed.
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.WriteString(output)
+	if err != nil {
+		_ = tmp.Close()
+		return xerrors.Errorf("write %q: %w", tmp.Name(), err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return xerrors.Errorf("close %q: %w", tmp.Name(), err)
+	}
+	// CreateTemp makes the file readable only by the owner.
+	err = os.Chmod(tmp.Name(), 0o644) //nolint:gosec // Generated code is not secret.
+	if err != nil {
+		return xerrors.Errorf("chmod %q: %w", tmp.Name(), err)
+	}
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return xerrors.Errorf("rename to %q: %w", path, err)
+	}
+	return nil
+}
+
+// checkOutput returns an error if the file at path is not output, eg
+// because the generated types are stale.
+func checkOutput(path string, output string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return xerrors.Errorf("read %q: %w", path, err)
+	}
+	if string(existing) != output {
+		return xerrors.Errorf("%q is out of date, regenerate it", path)
+	}
+	return nil
+}
@@ -0,0 +1,543 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// generatorSource is the source of the generator. Cached code blocks are
+// discarded when it changes, as they would be generated differently.
+//
+//go:embed *.go
+var generatorSource embed.FS
+
+// Kinds of cached code blocks, by the TypescriptTypes map they are in.
+const (
+	blockType    = "type"
+	blockEnum    = "enum"
+	blockGeneric = "generic"
+)
+
+var directiveWordRegex = regexp.MustCompile(`\w+`)
+
+// sinceCache is stored next to the file passed to -since. It holds the code
+// blocks of the last generation, and what each block depends on.
+type sinceCache struct {
+	// Generator identifies the generator source and options the blocks were
+	// generated with.
+	Generator string `json:"generator"`
+	// Started is when the packages were loaded. Files modified after have
+	// changed.
+	Started time.Time `json:"started"`
+	// Listing is the Go files in the directory of each generated package,
+	// to detect added and removed files.
+	Listing map[string][]string `json:"listing"`
+	// Files are checked for changes along with the listed files, eg go.mod.
+	Files []string `json:"files"`
+	// Mentions are the types each file of the generated packages mentions,
+	// including in @typescript directives and as the type of a constant.
+	Mentions map[string][]string    `json:"mentions"`
+	Blocks   map[string]cachedBlock `json:"blocks"`
+	// Builtins are the code blocks of builtin generic constraints.
+	Builtins map[string]string `json:"builtins"`
+}
+
+type cachedBlock struct {
+	Kind string `json:"kind"`
+	Code string `json:"code"`
+	// Names are the block and every type it depends on. The block is
+	// regenerated when a changed file mentions any of them.
+	Names []string `json:"names"`
+	// Files declare the block and the types it depends on.
+	Files []string `json:"files"`
+	// Builtins are the builtin generic constraints the block references.
+	Builtins []string `json:"builtins,omitempty"`
+	// Volatile blocks can depend on any type, and are regenerated when any
+	// file changed.
+	Volatile bool `json:"volatile,omitempty"`
+}
+
+// sinceState tracks the code blocks reused from the cache during a
+// generation. All methods are noops on a nil state, which is used when
+// Options.Since is not set.
+type sinceState struct {
+	path        string
+	fingerprint string
+	started     time.Time
+	// previous is nil when no code blocks can be reused.
+	previous *sinceCache
+	// changed are the files modified since the previous generation.
+	changed map[string]bool
+	// dirty are the types mentioned by a changed file, before or after it
+	// changed.
+	dirty map[string]bool
+
+	reused   map[string]bool
+	building string
+	builtins map[string]map[string]struct{}
+}
+
+// sinceCachePath returns the path of the cache for a generated file.
+func sinceCachePath(since string) string {
+	return filepath.Join(filepath.Dir(since), "."+filepath.Base(since)+".apitypings-cache")
+}
+
+// newSinceState reads the cache of the file passed to -since, and finds the
+// files that changed since it was written.
+func newSinceState(directory string, opts Options) (*sinceState, error) {
+	fingerprint, err := generatorFingerprint(directory, opts)
+	if err != nil {
+		return nil, xerrors.Errorf("fingerprint generator: %w", err)
+	}
+	s := &sinceState{
+		path:        sinceCachePath(opts.Since),
+		fingerprint: fingerprint,
+		started:     time.Now(),
+		reused:      make(map[string]bool),
+		builtins:    make(map[string]map[string]struct{}),
+	}
+	previous, err := readSinceCache(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil || previous.Generator != fingerprint {
+		// The blocks would be generated differently.
+		return s, nil
+	}
+	changed, listingChanged, err := previous.changes()
+	if err != nil {
+		return nil, err
+	}
+	if listingChanged {
+		// Added files can declare anything, so nothing is reused.
+		return s, nil
+	}
+	s.previous = previous
+	s.changed = changed
+	return s, nil
+}
+
+// unchanged returns true if no file the cached blocks depend on changed, so
+// the packages do not need to be loaded.
+func (s *sinceState) unchanged() bool {
+	return s != nil && s.previous != nil && len(s.changed) == 0
+}
+
+// markDirty marks the types mentioned by changed files, before and after
+// the change. mentions are the types mentioned by each file now.
+func (s *sinceState) markDirty(mentions map[string][]string) {
+	if s == nil || s.previous == nil {
+		return
+	}
+	s.dirty = make(map[string]bool)
+	for file := range s.changed {
+		for _, name := range s.previous.Mentions[file] {
+			s.dirty[name] = true
+		}
+		for _, name := range mentions[file] {
+			s.dirty[name] = true
+		}
+	}
+}
+
+// setBuilding sets the code block being generated, so the builtins it
+// references are cached with it.
+func (s *sinceState) setBuilding(name string) {
+	if s == nil {
+		return
+	}
+	s.building = name
+}
+
+func (s *sinceState) builtin(name string) {
+	if s == nil || s.building == "" {
+		return
+	}
+	if s.builtins[s.building] == nil {
+		s.builtins[s.building] = make(map[string]struct{})
+	}
+	s.builtins[s.building][name] = struct{}{}
+}
+
+// reuse returns the cached code block of a type if neither the files nor
+// the types it depends on changed. The builtins the block references are
+// added to the generator.
+func (g *Generator) reuse(name string) (cachedBlock, bool) {
+	s := g.since
+	if s == nil || s.previous == nil {
+		return cachedBlock{}, false
+	}
+	block, ok := s.previous.Blocks[name]
+	if !ok || block.Volatile {
+		return cachedBlock{}, false
+	}
+	for _, n := range block.Names {
+		if s.dirty[n] {
+			return cachedBlock{}, false
+		}
+	}
+	for _, file := range block.Files {
+		if s.changed[file] {
+			return cachedBlock{}, false
+		}
+	}
+	for _, builtin := range block.Builtins {
+		g.builtins[builtin] = s.previous.Builtins[builtin]
+	}
+	s.reused[name] = true
+	return block, true
+}
+
+// writeSinceCache caches the code blocks of the generation, before they are moved
+// into namespaces.
+func (g *Generator) writeSinceCache(codeBlocks *TypescriptTypes) error {
+	s := g.since
+	if s == nil {
+		return nil
+	}
+	listing := make(map[string][]string)
+	for _, pkg := range g.pkgs {
+		for _, file := range pkg.GoFiles {
+			dir := filepath.Dir(file)
+			if _, ok := listing[dir]; ok {
+				continue
+			}
+			files, err := goFiles(dir)
+			if err != nil {
+				return err
+			}
+			listing[dir] = files
+		}
+	}
+	// Types from other modules change with their version.
+	var files []string
+	if module := g.pkgs[0].Module; module != nil && module.GoMod != "" {
+		for _, file := range []string{module.GoMod, strings.TrimSuffix(module.GoMod, ".mod") + ".sum"} {
+			if _, err := os.Stat(file); err == nil {
+				files = append(files, file)
+			}
+		}
+	}
+
+	cache := sinceCache{
+		Generator: s.fingerprint,
+		Started:   s.started,
+		Listing:   listing,
+		Files:     files,
+		Mentions:  g.mentions(),
+		Blocks:    make(map[string]cachedBlock),
+		Builtins:  g.builtins,
+	}
+	add := func(kind string, blocks map[string]string) {
+		for name, code := range blocks {
+			if _, ok := g.builtins[name]; ok && kind == blockGeneric {
+				continue
+			}
+			if s.reused[name] {
+				cache.Blocks[name] = s.previous.Blocks[name]
+				continue
+			}
+			block := cachedBlock{
+				Kind:  kind,
+				Code:  code,
+				Names: []string{name},
+			}
+			if obj, ok := g.lookup(name).(*types.TypeName); ok {
+				block.Names, block.Files = g.dependencies(obj)
+				// Interfaces are a union of their implementers, which can
+				// be declared anywhere.
+				_, isInterface := obj.Type().Underlying().(*types.Interface)
+				block.Volatile = isInterface && g.opts.InterfaceUnions
+			}
+			for builtin := range s.builtins[name] {
+				block.Builtins = append(block.Builtins, builtin)
+			}
+			sort.Strings(block.Builtins)
+			cache.Blocks[name] = block
+		}
+	}
+	add(blockType, codeBlocks.Types)
+	add(blockEnum, codeBlocks.Enums)
+	add(blockGeneric, codeBlocks.Generics)
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return xerrors.Errorf("encode cache: %w", err)
+	}
+	return writeOutput(s.path, string(data))
+}
+
+// dependencies returns the names and files of the type and every type it
+// references, directly or through other types. Types from the standard
+// library are left out, as they only change with the Go version.
+func (g *Generator) dependencies(obj *types.TypeName) (names []string, files []string) {
+	seenNames := make(map[string]bool)
+	seenFiles := make(map[string]bool)
+	seen := make(map[*types.TypeName]bool)
+	var walk func(ty types.Type)
+	walk = func(ty types.Type) {
+		switch ty := ty.(type) {
+		case *types.Named:
+			obj := ty.Obj()
+			if seen[obj] {
+				return
+			}
+			seen[obj] = true
+			for i := 0; i < ty.TypeArgs().Len(); i++ {
+				walk(ty.TypeArgs().At(i))
+			}
+			if obj.Pkg() == nil || !strings.Contains(strings.Split(obj.Pkg().Path(), "/")[0], ".") {
+				return
+			}
+			seenNames[obj.Name()] = true
+			if file := g.pkgs[0].Fset.Position(obj.Pos()).Filename; file != "" {
+				seenFiles[file] = true
+			}
+			for i := 0; i < ty.TypeParams().Len(); i++ {
+				walk(ty.TypeParams().At(i).Constraint())
+			}
+			walk(ty.Underlying())
+		case *types.Pointer:
+			walk(ty.Elem())
+		case *types.Slice:
+			walk(ty.Elem())
+		case *types.Array:
+			walk(ty.Elem())
+		case *types.Map:
+			walk(ty.Key())
+			walk(ty.Elem())
+		case *types.Struct:
+			for i := 0; i < ty.NumFields(); i++ {
+				walk(ty.Field(i).Type())
+			}
+		case *types.Interface:
+			for i := 0; i < ty.NumEmbeddeds(); i++ {
+				walk(ty.EmbeddedType(i))
+			}
+		case *types.Union:
+			for i := 0; i < ty.Len(); i++ {
+				walk(ty.Term(i).Type())
+			}
+		case *types.TypeParam:
+			walk(ty.Constraint())
+		}
+	}
+	walk(obj.Type())
+	// Types of the generated packages are always dependencies of
+	// themselves, even if declared in a package without a dot.
+	seenNames[obj.Name()] = true
+	if file := g.pkgs[0].Fset.Position(obj.Pos()).Filename; file != "" {
+		seenFiles[file] = true
+	}
+	return sortedKeys(seenNames), sortedKeys(seenFiles)
+}
+
+// mentions returns the types mentioned by each file of the generated
+// packages. A type is mentioned by an identifier, a word in a @typescript
+// directive, or as the type of a constant declared in the file.
+func (g *Generator) mentions() map[string][]string {
+	typeNames := make(map[string]bool)
+	for _, pkg := range g.pkgs {
+		for _, name := range pkg.Types.Scope().Names() {
+			if _, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName); ok {
+				typeNames[name] = true
+			}
+		}
+	}
+
+	mentions := make(map[string][]string)
+	for _, pkg := range g.pkgs {
+		for _, file := range pkg.Syntax {
+			names := make(map[string]bool)
+			ast.Inspect(file, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.Ident:
+					if typeNames[node.Name] {
+						names[node.Name] = true
+					}
+				case *ast.ValueSpec:
+					// Constants can have the type of a constant declared
+					// elsewhere without naming it.
+					for _, ident := range node.Names {
+						c, ok := pkg.TypesInfo.Defs[ident].(*types.Const)
+						if !ok {
+							continue
+						}
+						if named, ok := c.Type().(*types.Named); ok {
+							names[named.Obj().Name()] = true
+						}
+					}
+				}
+				return true
+			})
+			for _, group := range file.Comments {
+				for _, comment := range group.List {
+					if !strings.Contains(comment.Text, "@typescript") {
+						continue
+					}
+					for _, word := range directiveWordRegex.FindAllString(comment.Text, -1) {
+						names[word] = true
+					}
+				}
+			}
+			filename := pkg.Fset.File(file.Pos()).Name()
+			mentions[filename] = sortedKeys(names)
+		}
+	}
+	return mentions
+}
+
+// codeBlocks returns the cached code blocks.
+func (c *sinceCache) codeBlocks(opts Options) *TypescriptTypes {
+	t := &TypescriptTypes{
+		Types:    make(map[string]string),
+		Enums:    make(map[string]string),
+		Generics: make(map[string]string),
+	}
+	for name, block := range c.Blocks {
+		switch block.Kind {
+		case blockType:
+			t.Types[name] = block.Code
+		case blockEnum:
+			t.Enums[name] = block.Code
+		case blockGeneric:
+			t.Generics[name] = block.Code
+		}
+	}
+	for name, value := range c.Builtins {
+		if value != "" {
+			t.Generics[name] = value
+		}
+	}
+	if opts.EmitEnumRegistry {
+		t.EnumRegistry = buildEnumRegistry(t.Enums)
+	}
+	return t
+}
+
+// changes returns the files modified since the cache was written, and
+// whether files were added to or removed from the package directories.
+func (c *sinceCache) changes() (changed map[string]bool, listingChanged bool, err error) {
+	check := append([]string{}, c.Files...)
+	for dir, listed := range c.Listing {
+		files, err := goFiles(dir)
+		if err != nil {
+			return nil, false, err
+		}
+		if strings.Join(files, "\n") != strings.Join(listed, "\n") {
+			return nil, true, nil
+		}
+		for _, file := range files {
+			check = append(check, filepath.Join(dir, file))
+		}
+	}
+	for _, block := range c.Blocks {
+		check = append(check, block.Files...)
+	}
+
+	changed = make(map[string]bool)
+	for _, file := range check {
+		if _, ok := changed[file]; ok {
+			continue
+		}
+		info, err := os.Stat(file)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			changed[file] = true
+		case err != nil:
+			return nil, false, xerrors.Errorf("stat %q: %w", file, err)
+		default:
+			changed[file] = info.ModTime().After(c.Started)
+		}
+	}
+	for file, ok := range changed {
+		if !ok {
+			delete(changed, file)
+		}
+	}
+	return changed, false, nil
+}
+
+// readSinceCache returns the cache at path, or nil if there is none.
+func readSinceCache(path string) (*sinceCache, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("read cache: %w", err)
+	}
+	var cache sinceCache
+	err = json.Unmarshal(data, &cache)
+	if err != nil {
+		// An unreadable cache is regenerated.
+		return nil, nil //nolint:nilerr
+	}
+	return &cache, nil
+}
+
+// generatorFingerprint identifies the generator source, the Go version and
+// the options, which all change how code blocks are generated.
+func generatorFingerprint(directory string, opts Options) (string, error) {
+	hash := sha256.New()
+	err := fs.WalkDir(generatorSource, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := generatorSource.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(hash, "%s\n%d\n", path, len(data))
+		_, _ = hash.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	opts.Since = ""
+	opts.PostProcess = nil
+	_, _ = fmt.Fprintf(hash, "%s\n%s\n%#v\n", runtime.Version(), directory, opts)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// goFiles returns the sorted names of the Go files in dir.
+func goFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("read dir %q: %w", dir, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,17 @@
+package codersdk
+
+// Username is any valid username, it is not an enum.
+type Username string
+
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+)
+
+type User struct {
+	Username Username   `json:"username"`
+	Status   Status     `json:"status"`
+	Previous []Username `json:"previous"`
+}
@@ -0,0 +1,6 @@
+package codersdk
+
+type File struct {
+	Hash [32]byte `json:"hash"`
+	Data []byte   `json:"data"`
+}
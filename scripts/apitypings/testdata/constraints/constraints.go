@@ -0,0 +1,37 @@
+package codersdk
+
+type Integer interface {
+	~int | ~int64
+}
+
+type Text interface {
+	~string
+}
+
+// Scalar is composed of two embedded interfaces.
+type Scalar interface {
+	Integer | Text | bool
+}
+
+// Value nests Scalar, so it is flattened too.
+type Value interface {
+	Scalar | []string
+}
+
+// Named is the types in both Scalar and the union.
+type Named interface {
+	Scalar
+	~string | float64
+}
+
+// Empty has no types in both Integer and Text.
+type Empty interface {
+	Integer
+	Text
+}
+
+type Labels[S Scalar, V Value, N Named] struct {
+	Scalar S `json:"scalar"`
+	Value  V `json:"value"`
+	Named  N `json:"named"`
+}
@@ -0,0 +1,13 @@
+package audit
+
+import "github.com/coder/coder/scripts/apitypings/testdata/crossembed/other"
+
+type Actor struct {
+	Name string `json:"name"`
+}
+
+type Event struct {
+	Actor
+	other.Timestamps
+	Action string `json:"action"`
+}
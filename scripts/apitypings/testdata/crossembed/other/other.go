@@ -0,0 +1,7 @@
+// Package other is embedded by the crossembed fixture.
+package other
+
+type Timestamps struct {
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package codersdk
+
+type ParameterKind string
+
+const ParameterKindList ParameterKind = "list"
+
+type StringParameter struct {
+	Type    string `json:"type" typescript:"\"string\""`
+	Default string `json:"default"`
+}
+
+type NumberParameter struct {
+	Type    string  `json:"type" typescript:"\"number\""`
+	Default float64 `json:"default"`
+}
+
+type ListParameter struct {
+	Type    ParameterKind `json:"type"`
+	Options []string      `json:"options"`
+}
+
+// Parameter is sent with the type of parameter in the "type" field.
+// @typescript-discriminator:type
+type Parameter interface {
+	StringParameter | NumberParameter | ListParameter
+}
+
+type Template[P Parameter] struct {
+	Parameters []P `json:"parameters"`
+}
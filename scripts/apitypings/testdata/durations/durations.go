@@ -0,0 +1,11 @@
+package codersdk
+
+import "time"
+
+type Deadline struct {
+	Timeout  time.Duration            `json:"timeout"`
+	Grace    *time.Duration           `json:"grace,omitempty"`
+	Retries  []time.Duration          `json:"retries"`
+	Interval time.Duration            `json:"interval" typescript:",milliseconds"`
+	ByStage  map[string]time.Duration `json:"by_stage"`
+}
@@ -0,0 +1,27 @@
+package codersdk
+
+// Base is embedded by Middle, which is embedded by Leaf.
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Middle struct {
+	Base
+	Name string `json:"name"`
+}
+
+type Leaf struct {
+	Middle
+	Value int `json:"value"`
+}
+
+// @typescript-ignore IgnoredMiddle
+type IgnoredMiddle struct {
+	Base
+	Hidden string `json:"hidden"`
+}
+
+type IgnoredLeaf struct {
+	IgnoredMiddle
+	Value int `json:"value"`
+}
@@ -0,0 +1,22 @@
+// Code generated by 'make site/src/api/typesGenerated.ts'. DO NOT EDIT.
+
+// From codersdk/embedchain.go
+export interface Base {
+  readonly id: string
+}
+
+// From codersdk/embedchain.go
+export interface IgnoredLeaf extends Base {
+  readonly hidden: string
+  readonly value: number
+}
+
+// From codersdk/embedchain.go
+export interface Leaf extends Middle {
+  readonly value: number
+}
+
+// From codersdk/embedchain.go
+export interface Middle extends Base {
+  readonly name: string
+}
@@ -0,0 +1,15 @@
+package codersdk
+
+type Audit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type Pagination struct {
+	Limit int `json:"limit"`
+}
+
+type ListRequest struct {
+	Pagination
+	*Audit
+	Search string `json:"search"`
+}
@@ -0,0 +1,17 @@
+package codersdk
+
+// @typescript-enum-group:BuildReason
+const (
+	BuildReasonInitiator = "initiator"
+	BuildReasonAutostart = "autostart"
+	BuildReasonAutostop  = "autostop"
+)
+
+// Ungrouped constants are not generated.
+const (
+	DefaultPageSize = "25"
+)
+
+type Build struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,16 @@
+package codersdk
+
+// BuildStatus progresses in declaration order.
+type BuildStatus string
+
+const (
+	BuildStatusPending  BuildStatus = "pending"
+	BuildStatusStarting BuildStatus = "starting"
+	BuildStatusRunning  BuildStatus = "running"
+	BuildStatusStopping BuildStatus = "stopping"
+	BuildStatusDone     BuildStatus = "done"
+)
+
+// Level is listed highest first.
+// @typescript-enum-values:"high","medium","low"
+type Level string
@@ -0,0 +1,27 @@
+package codersdk
+
+// LogLevel is sent over the wire as a string, see MarshalJSON.
+// @typescript-enum-values:"debug","info","error"
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelError
+)
+
+func (l LogLevel) MarshalJSON() ([]byte, error) {
+	switch l {
+	case LogLevelDebug:
+		return []byte(`"debug"`), nil
+	case LogLevelInfo:
+		return []byte(`"info"`), nil
+	default:
+		return []byte(`"error"`), nil
+	}
+}
+
+type Log struct {
+	Level  LogLevel `json:"level"`
+	Output string   `json:"output"`
+}
@@ -0,0 +1,8 @@
+package codersdk
+
+type BuildResult struct {
+	Output string `json:"output"`
+	// Error is marshaled as the error message.
+	Error  error   `json:"error"`
+	Errors []error `json:"errors"`
+}
@@ -0,0 +1,16 @@
+package codersdk
+
+type Separator string
+
+const (
+	SeparatorQuote     Separator = `"`
+	SeparatorBackslash Separator = `\`
+	SeparatorNewline   Separator = "\n"
+	SeparatorBell      Separator = "\a"
+	SeparatorTag       Separator = "<br>"
+	SeparatorLong      Separator = "a separator that is long enough to be shortened by constant.Value.String"
+)
+
+type Split struct {
+	Separator Separator `json:"separator"`
+}
@@ -0,0 +1,23 @@
+package codersdk
+
+import "time"
+
+type Workspace struct {
+	// ID is the workspace's unique identifier.
+	ID string `json:"id"`
+	// Deadline is the time the workspace stops.
+	//
+	// It is extended by activity, see
+	// https://coder.com/docs/workspaces.
+	Deadline time.Time `json:"deadline"`
+	// Ttl is how long the workspace runs for.
+	Ttl time.Duration `json:"ttl"`
+	// Glob matches paths such as "a/*/b", and "*/" must not end the comment.
+	Glob string `json:"glob"`
+	// Owner and Name are both documented by this comment.
+	Owner, Name string
+	//nolint:revive // Directives are not documentation.
+	Template     string `json:"template"`
+	Region       string `json:"region"` // Trailing comments are not documentation.
+	Undocumented string `json:"undocumented"`
+}
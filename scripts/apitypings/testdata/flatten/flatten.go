@@ -0,0 +1,26 @@
+package codersdk
+
+import "time"
+
+type Timestamps struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Owner struct {
+	OwnerID   string `json:"owner_id"`
+	OwnerName string `json:"owner_name"`
+}
+
+type Audited struct {
+	Timestamps
+	AuditedBy string `json:"audited_by"`
+}
+
+type Workspace struct {
+	ID string `json:"id"`
+	Audited
+	Name string `json:"name"`
+	*Owner
+	Status string `json:"status"`
+}
@@ -0,0 +1,17 @@
+package codersdk
+
+// Tier is the price per hour of a workspace.
+type Tier float64
+
+const (
+	TierFree     Tier = 0
+	TierStandard Tier = 0.1
+	TierLarge    Tier = 1.25
+	TierPrecise  Tier = 0.123456789
+	TierTiny     Tier = 1e-7
+)
+
+type Quote struct {
+	Tier      Tier `json:"tier"`
+	TierQuote Tier `json:"tier_quote,string"`
+}
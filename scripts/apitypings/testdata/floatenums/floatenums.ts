@@ -0,0 +1,11 @@
+// Code generated by 'make site/src/api/typesGenerated.ts'. DO NOT EDIT.
+
+// From codersdk/floatenums.go
+export interface Quote {
+  readonly tier: Tier
+  readonly tier_quote: "0" | "0.1" | "0.123456789" | "1.25" | "1e-7"
+}
+
+// From codersdk/floatenums.go
+export type Tier = 0 | 1e-7 | 0.1 | 0.123456789 | 1.25
+export const Tiers: Tier[] = [0, 1e-7, 0.1, 0.123456789, 1.25]
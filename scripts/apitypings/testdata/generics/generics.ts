@@ -19,8 +19,8 @@ export interface DynamicGeneric<A extends any, S extends Single> {
 export interface GenericFields<C extends comparable, A extends any, T extends Custom, S extends Single> {
   readonly comparable: C
   readonly any: A
-  readonly custom: T
-  readonly again: T
+  readonly custom?: T
+  readonly again?: T
   readonly single_constraint: S
 }
 
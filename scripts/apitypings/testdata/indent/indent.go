@@ -0,0 +1,9 @@
+package indent
+
+type Indented struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+	Nested struct {
+		Field string `json:"field"`
+	} `json:"nested"`
+}
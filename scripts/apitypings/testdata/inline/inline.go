@@ -0,0 +1,17 @@
+package codersdk
+
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset,omitempty"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type ListUsersRequest struct {
+	Search     string     `json:"search"`
+	Pagination Pagination `json:"pagination" typescript:",inline"`
+	Audit      *Audit     `json:"audit" typescript:",inline"`
+	Nested     Pagination `json:"nested"`
+}
@@ -0,0 +1,10 @@
+package codersdk
+
+type Quota struct {
+	Used      int32   `json:"used"`
+	Limit     int64   `json:"limit"`
+	Bytes     uint64  `json:"bytes"`
+	Count     int     `json:"count"`
+	History   []int64 `json:"history"`
+	LimitText int64   `json:"limit_text,string"`
+}
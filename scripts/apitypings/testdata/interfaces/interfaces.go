@@ -0,0 +1,28 @@
+package codersdk
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c Circle) Area() float64 { return 3.14 * c.Radius * c.Radius }
+
+type Square struct {
+	Side float64 `json:"side"`
+}
+
+func (s *Square) Area() float64 { return s.Side * s.Side }
+
+// Label does not implement Shape.
+type Label struct {
+	Text string `json:"text"`
+}
+
+type Drawing struct {
+	Shapes []Shape `json:"shapes"`
+	Main   Shape   `json:"main"`
+	Label  Label   `json:"label"`
+}
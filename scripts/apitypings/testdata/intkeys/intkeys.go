@@ -0,0 +1,18 @@
+package codersdk
+
+type Priority int
+
+const (
+	PriorityLow  Priority = 1
+	PriorityHigh Priority = 2
+)
+
+type Port struct {
+	Name string `json:"name"`
+}
+
+type Listeners struct {
+	Ports      map[int]Port      `json:"ports"`
+	ByPriority map[Priority]Port `json:"by_priority"`
+	ByName     map[string]Port   `json:"by_name"`
+}
@@ -0,0 +1,9 @@
+package codersdk
+
+import "encoding/json"
+
+type Measurement struct {
+	Name    string        `json:"name"`
+	Value   json.Number   `json:"value"`
+	Samples []json.Number `json:"samples"`
+}
@@ -0,0 +1,16 @@
+package codersdk
+
+type Resource struct {
+	Name string `json:"name"`
+}
+
+type Maps struct {
+	Strings    map[string]string      `json:"strings"`
+	Numbers    map[string]int64       `json:"numbers"`
+	Lists      map[string][]string    `json:"lists"`
+	Structs    map[string]Resource    `json:"structs"`
+	Pointers   map[string]*Resource   `json:"pointers"`
+	Any        map[string]interface{} `json:"any"`
+	Optional   map[string]bool        `json:"optional,omitempty"`
+	NestedList []map[string]string    `json:"nested_list"`
+}
@@ -0,0 +1,27 @@
+package codersdk
+
+// Secret hides the value when marshaled.
+type Secret struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (s *Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`{"name":"` + s.Name + `"}`), nil
+}
+
+// Counts are marshaled as a map of name to count.
+// @typescript-raw:"Record<string, number>"
+type Counts struct {
+	names  []string
+	counts []int
+}
+
+func (c Counts) MarshalJSON() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+type Report struct {
+	Secret Secret `json:"secret"`
+	Counts Counts `json:"counts"`
+}
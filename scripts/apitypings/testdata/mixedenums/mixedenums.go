@@ -0,0 +1,29 @@
+package codersdk
+
+type LogLevel int
+
+const (
+	LogLevelTrace LogLevel = iota
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelFatal
+	LogLevelPanic
+	LogLevelSilent
+	LogLevelAudit
+	LogLevelStats
+	LogLevelMetrics
+)
+
+type LogSource string
+
+const (
+	LogSourceAgent       LogSource = "agent"
+	LogSourceProvisioner LogSource = "provisioner"
+)
+
+type Log struct {
+	Level  LogLevel  `json:"level"`
+	Source LogSource `json:"source"`
+}
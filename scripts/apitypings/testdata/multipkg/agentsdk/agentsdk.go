@@ -0,0 +1,23 @@
+package agentsdk
+
+import codersdk "github.com/coder/coder/scripts/apitypings/testdata/multipkg"
+
+// DefaultLifecycle is not a value of the enum, as it is declared in a
+// different package.
+const DefaultLifecycle codersdk.WorkspaceAgentLifecycle = "default"
+
+type PostLifecycleRequest struct {
+	State codersdk.WorkspaceAgentLifecycle `json:"state"`
+}
+
+type Manifest struct {
+	Agent  codersdk.WorkspaceAgent `json:"agent"`
+	Health Health                  `json:"health"`
+}
+
+type Health string
+
+const (
+	HealthConnected    Health = "connected"
+	HealthDisconnected Health = "disconnected"
+)
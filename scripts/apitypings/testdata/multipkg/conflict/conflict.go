@@ -0,0 +1,6 @@
+package conflict
+
+// WorkspaceAgent has the same name as a type in codersdk.
+type WorkspaceAgent struct {
+	ID string `json:"id"`
+}
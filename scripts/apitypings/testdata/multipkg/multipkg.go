@@ -0,0 +1,13 @@
+package codersdk
+
+type WorkspaceAgentLifecycle string
+
+const (
+	WorkspaceAgentLifecycleCreated WorkspaceAgentLifecycle = "created"
+	WorkspaceAgentLifecycleReady   WorkspaceAgentLifecycle = "ready"
+)
+
+type WorkspaceAgent struct {
+	Name      string                  `json:"name"`
+	Lifecycle WorkspaceAgentLifecycle `json:"lifecycle"`
+}
@@ -0,0 +1,21 @@
+package codersdk
+
+type Workspace struct {
+	LatestBuild WorkspaceBuild `json:"latest_build"`
+}
+
+type WorkspaceBuild struct {
+	Status   WorkspaceStatus `json:"status"`
+	Template Template        `json:"template"`
+}
+
+type WorkspaceStatus string
+
+const (
+	WorkspaceStatusRunning WorkspaceStatus = "running"
+	WorkspaceStatusStopped WorkspaceStatus = "stopped"
+)
+
+type Template struct {
+	Workspaces []Workspace `json:"workspaces"`
+}
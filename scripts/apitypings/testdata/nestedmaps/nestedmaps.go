@@ -0,0 +1,14 @@
+package codersdk
+
+type Usage struct {
+	Counts  map[string]map[string]int             `json:"counts"`
+	Deep    map[string]map[string]map[string]bool `json:"deep"`
+	Lists   map[string]map[string][]string        `json:"lists"`
+	Values  map[string]map[string]interface{}     `json:"values"`
+	Structs map[string]map[string]Resource        `json:"structs"`
+	Ptrs    map[string]map[string]*Resource       `json:"ptrs"`
+}
+
+type Resource struct {
+	Name string `json:"name"`
+}
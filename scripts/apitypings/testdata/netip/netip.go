@@ -0,0 +1,14 @@
+package codersdk
+
+import (
+	"net"
+	"net/netip"
+)
+
+type Network struct {
+	IP        net.IP       `json:"ip"`
+	Addr      netip.Addr   `json:"addr"`
+	Prefix    netip.Prefix `json:"prefix"`
+	IPNet     net.IPNet    `json:"ip_net"`
+	Addresses []net.IP     `json:"addresses"`
+}
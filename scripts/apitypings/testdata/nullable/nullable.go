@@ -0,0 +1,15 @@
+package codersdk
+
+type User struct {
+	Name          string       `json:"name"`
+	Nickname      *string      `json:"nickname"`
+	Bio           string       `json:"bio,omitempty"`
+	AvatarURL     *string      `json:"avatar_url,omitempty"`
+	NotNullString *string      `json:"not_null" typescript:",notnull"`
+	Role          string       `json:"role" typescript:",optional"`
+	Workspaces    []*Workspace `json:"workspaces"`
+}
+
+type Workspace struct {
+	Name string `json:"name"`
+}
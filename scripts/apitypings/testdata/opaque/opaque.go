@@ -0,0 +1,16 @@
+package codersdk
+
+import "sync"
+
+// @typescript-ignore Cache
+type Cache struct {
+	entries map[string]string
+	mu      sync.Mutex
+}
+
+type Workspace struct {
+	Name    string    `json:"name"`
+	Cache   *Cache    `json:"cache" typescript:",opaque"`
+	Callers sync.Map  `json:"callers" typescript:",opaque"`
+	Details chan bool `json:"details" typescript:",opaque"`
+}
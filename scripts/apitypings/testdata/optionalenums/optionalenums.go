@@ -0,0 +1,16 @@
+package codersdk
+
+type WorkspaceStatus string
+
+const (
+	WorkspaceStatusPending WorkspaceStatus = "pending"
+	WorkspaceStatusRunning WorkspaceStatus = "running"
+)
+
+type WorkspaceFilter struct {
+	Status   *WorkspaceStatus            `json:"status"`
+	Previous *WorkspaceStatus            `json:"previous,omitempty"`
+	Current  WorkspaceStatus             `json:"current"`
+	History  []*WorkspaceStatus          `json:"history"`
+	ByAgent  map[string]*WorkspaceStatus `json:"by_agent"`
+}
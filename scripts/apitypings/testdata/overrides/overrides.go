@@ -0,0 +1,14 @@
+package codersdk
+
+// Date is marshaled as YYYY-MM-DD.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+type License struct {
+	ExpiresAt Date   `json:"expires_at"`
+	StartsAt  *Date  `json:"starts_at"`
+	Holidays  []Date `json:"holidays"`
+}
@@ -0,0 +1,22 @@
+package codersdk
+
+type Foo struct {
+	Name string `json:"name"`
+}
+
+type Key struct {
+	ID string `json:"id"`
+}
+
+type PointerMaps struct {
+	// The map can be null, the values cannot.
+	PointerToMap *map[string]Foo `json:"pointer_to_map"`
+	// The values can be null, the map is never null.
+	MapOfPointers map[string]*Foo `json:"map_of_pointers"`
+	// Both the map and its values can be null.
+	PointerToMapOfPointers *map[string]*Foo `json:"pointer_to_map_of_pointers"`
+	// The values are maps that can be null.
+	MapOfPointerToMap map[string]*map[string]Foo `json:"map_of_pointer_to_map"`
+	OmittedMap        *map[string]*Foo           `json:"omitted_map,omitempty"`
+	Pairs             map[Key]*Foo               `json:"pairs" typescript:",tuples"`
+}
@@ -0,0 +1,16 @@
+package codersdk
+
+type Level int
+
+const (
+	LevelLow  Level = 1
+	LevelHigh Level = 2
+)
+
+type Alert struct {
+	Level       Level  `json:"level"`
+	QuotedLevel Level  `json:"quoted_level,string"`
+	Count       int64  `json:"count,string"`
+	Enabled     bool   `json:"enabled,string"`
+	Name        string `json:"name,string"`
+}
@@ -0,0 +1,12 @@
+package codersdk
+
+import "encoding/json"
+
+type Event struct {
+	Payload  json.RawMessage            `json:"payload"`
+	Previous *json.RawMessage           `json:"previous"`
+	Extra    json.RawMessage            `json:"extra,omitempty"`
+	Batch    []json.RawMessage          `json:"batch"`
+	Fields   json.RawMessage            `json:"fields" typescript:"Record<string, string>"`
+	ByName   map[string]json.RawMessage `json:"by_name"`
+}
@@ -0,0 +1,24 @@
+package codersdk
+
+// BuildReason is the reason a build was started.
+// @typescript-enum
+type BuildReason string
+
+const (
+	BuildReasonInitiator BuildReason = "initiator"
+	BuildReasonAutostart BuildReason = "autostart"
+	BuildReasonAutostop  BuildReason = "autostop"
+)
+
+// LogLevel is a union, the default.
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+)
+
+type Build struct {
+	Reason BuildReason `json:"reason"`
+	Level  LogLevel    `json:"level"`
+}
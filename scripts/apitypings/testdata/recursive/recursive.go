@@ -0,0 +1,17 @@
+package codersdk
+
+// Primitive is a leaf of a JSON like value. Go does not allow a union to
+// reference itself, so the recursion is in the struct.
+type Primitive interface {
+	string | int | bool
+}
+
+type JSONValue[P Primitive] struct {
+	Value  *P                      `json:"value,omitempty"`
+	Array  []JSONValue[P]          `json:"array,omitempty"`
+	Object map[string]JSONValue[P] `json:"object,omitempty"`
+}
+
+type Document struct {
+	Root JSONValue[string] `json:"root"`
+}
@@ -0,0 +1,17 @@
+package codersdk
+
+type Resource struct {
+	Name string `json:"name"`
+}
+
+type Matrix struct {
+	Rows       []map[string]int              `json:"rows"`
+	Columns    map[string][]int              `json:"columns"`
+	Grid       [][]map[string]int            `json:"grid"`
+	Values     []map[string]interface{}      `json:"values"`
+	AnyLists   map[string][]interface{}      `json:"any_lists"`
+	Resources  []map[string]*Resource        `json:"resources"`
+	ByResource map[string][]*Resource        `json:"by_resource"`
+	Fixed      [2]map[string]string          `json:"fixed"`
+	Nested     []map[string][]map[string]int `json:"nested"`
+}
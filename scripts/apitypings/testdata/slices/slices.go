@@ -0,0 +1,17 @@
+package codersdk
+
+type TemplateVersion struct {
+	Name string `json:"name"`
+}
+
+type TemplateVersions []TemplateVersion
+type TemplateVersionPointers []*TemplateVersion
+type Checksum [4]TemplateVersion
+type Blob []byte
+type Blobs []Blob
+type Matrix [][]string
+
+type Template struct {
+	Versions TemplateVersions `json:"versions"`
+	Blobs    Blobs            `json:"blobs"`
+}
@@ -0,0 +1,9 @@
+package codersdk
+
+import "time"
+
+type Schedule struct {
+	Days   []time.Weekday `json:"days"`
+	Start  time.Weekday   `json:"start"`
+	Months []time.Month   `json:"months"`
+}
@@ -0,0 +1,31 @@
+package codersdk
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID        uuid.UUID  `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	Admin     bool       `json:"admin"`
+	Age       int        `json:"age"`
+	Tags      []string   `json:"tags"`
+	Password  string     `json:"-"`
+	Profile   Profile    `json:"profile"`
+	// Untagged fields use the Go name.
+	Untagged string
+}
+
+type Profile struct {
+	DisplayName string  `json:"display_name"`
+	Avatar      *string `json:"avatar,omitempty"`
+	Friends     []*User `json:"friends"`
+	Scores      [3]int  `json:"scores"`
+	Size        float64 `json:"size"`
+	Raw         []byte  `json:"raw"`
+}
@@ -0,0 +1,5 @@
+package testfiles
+
+type Shape struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,7 @@
+package testfiles
+
+// ExampleResponse is only declared in a test file, so it is only generated
+// when tests are included.
+type ExampleResponse struct {
+	Shapes []Shape `json:"shapes"`
+}
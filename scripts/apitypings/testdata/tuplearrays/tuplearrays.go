@@ -0,0 +1,12 @@
+package codersdk
+
+type Color struct {
+	RGB     [3]int   `json:"rgb"`
+	History []int    `json:"history"`
+	Samples [64]int  `json:"samples"`
+	Names   [2]*Name `json:"names"`
+}
+
+type Name struct {
+	Value string `json:"value"`
+}
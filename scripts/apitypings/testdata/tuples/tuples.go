@@ -0,0 +1,12 @@
+package codersdk
+
+type PortKey struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type PortShares struct {
+	// Shares marshals to an array of [PortKey, level] pairs.
+	Shares map[PortKey]string `json:"shares" typescript:",tuples"`
+	Labels map[string]string  `json:"labels"`
+}
@@ -0,0 +1,15 @@
+package unions
+
+// Nullable can be null, since it includes a pointer.
+type Nullable interface {
+	string | *int
+}
+
+type NotNull interface {
+	string | int
+}
+
+type Response[N Nullable, V NotNull] struct {
+	Nullable N `json:"nullable"`
+	Value    V `json:"value"`
+}
@@ -0,0 +1,9 @@
+package codersdk
+
+import "github.com/google/uuid"
+
+type Replicas struct {
+	IDs     [4]uuid.UUID `json:"ids"`
+	Primary uuid.UUID    `json:"primary"`
+	Others  []uuid.UUID  `json:"others"`
+}
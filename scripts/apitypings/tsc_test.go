@@ -0,0 +1,55 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/testutil"
+)
+
+// TestTypescriptCompiles runs the generated output for each fixture through
+// the typescript compiler. Some generation bugs (invalid identifiers, cyclic
+// unions) produce output that only fails once the frontend compiles it. This
+// test is skipped if 'tsc' is not installed.
+func TestTypescriptCompiles(t *testing.T) {
+	t.Parallel()
+	tsc, err := exec.LookPath("tsc")
+	if err != nil {
+		t.Skip("tsc not found in PATH")
+	}
+
+	files, err := os.ReadDir("testdata")
+	require.NoError(t, err, "read dir")
+
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		f := f
+		t.Run(f.Name(), func(t *testing.T) {
+			t.Parallel()
+			dir := filepath.Join(".", "testdata", f.Name())
+			output, err := Generate("./"+dir, Options{})
+			require.NoErrorf(t, err, "generate %q", dir)
+
+			file := filepath.Join(t.TempDir(), f.Name()+".ts")
+			err = os.WriteFile(file, []byte(output), 0o600)
+			require.NoError(t, err, "write generated file")
+
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+			defer cancel()
+			//nolint:gosec // The path is from LookPath.
+			cmd := exec.CommandContext(ctx, tsc, "--noEmit", "--strict", "--target", "es2020", file)
+			out, err := cmd.CombinedOutput()
+			require.NoErrorf(t, err, "tsc failed:\n%s", out)
+		})
+	}
+}
@@ -59,9 +59,7 @@ export interface AuditLog {
   readonly request_id: string
   readonly time: string
   readonly organization_id: string
-  // Named type "net/netip.Addr" unknown, using "any"
-  // eslint-disable-next-line @typescript-eslint/no-explicit-any -- TODO explain why this is needed
-  readonly ip: any
+  readonly ip: string
   readonly user_agent: string
   readonly resource_type: ResourceType
   readonly resource_id: string
@@ -207,7 +205,7 @@ export interface CreateTestAuditLogRequest {
 
 // From codersdk/apikey.go
 export interface CreateTokenRequest {
-  // This is likely an enum in an external package ("time.Duration")
+  // time.Duration, in nanoseconds
   readonly lifetime: number
   readonly scope: APIKeyScope
 }
@@ -323,6 +321,7 @@ export interface DeploymentConfig {
 }
 
 // From codersdk/deployment.go
+// DeploymentConfigField implements json.Marshaler, this type might not match the JSON.
 export interface DeploymentConfigField<T extends Flaggable> {
   readonly name: string
   readonly usage: string